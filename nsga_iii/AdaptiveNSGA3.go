@@ -0,0 +1,94 @@
+package nsga_iii
+
+import (
+	"math"
+	"strconv"
+)
+
+// adaptReferencePoints adds a simplex of reference points around every reference point
+// associated with at least two individuals of the population, and removes the added
+// reference points no individual is associated with. The reference points generated
+// at the start of the run are never removed. It returns the niche counts of the adapted
+// reference points.
+func (nsga3 *NSGA3) adaptReferencePoints(population Population, nicheCounts map[string]int) map[string]int {
+	numberOfObjectiveFunctions := len(population[0].ObjectiveValues)
+	isAnyReferencePointAdded := false
+	for _, referencePoint := range append([]*ReferencePoint{}, nsga3.referencePoints...) {
+		if nicheCounts[referencePoint.ID] < 2 {
+			continue
+		}
+		for i := 0; i < numberOfObjectiveFunctions; i++ {
+			coordinates, isValid := nsga3.simplexVertex(*referencePoint, i)
+			if !isValid || nsga3.hasReferencePointAt(coordinates) {
+				continue
+			}
+			nsga3.numberOfAddedReferencePoints++
+			addedReferencePoint := &ReferencePoint{ID: "added-reference-point-" + strconv.Itoa(nsga3.numberOfAddedReferencePoints), Coordinates: coordinates}
+			nsga3.referencePoints = append(nsga3.referencePoints, addedReferencePoint)
+			nsga3.addedReferencePointIDs[addedReferencePoint.ID] = true
+			isAnyReferencePointAdded = true
+		}
+	}
+	if isAnyReferencePointAdded {
+		nicheCounts = nsga3.computeNicheCounts(population, nsga3.referencePoints)
+	}
+
+	var referencePoints []*ReferencePoint
+	for _, referencePoint := range nsga3.referencePoints {
+		if nicheCounts[referencePoint.ID] == 0 && nsga3.addedReferencePointIDs[referencePoint.ID] {
+			delete(nsga3.addedReferencePointIDs, referencePoint.ID)
+			delete(nicheCounts, referencePoint.ID)
+			continue
+		}
+		referencePoints = append(referencePoints, referencePoint)
+	}
+	nsga3.referencePoints = referencePoints
+	return nicheCounts
+}
+
+// simplexVertex returns the i-th vertex of the simplex centered on the reference point
+// whose edges are as long as the gap between neighboring reference points, and whether
+// it lies on the positive side of every objective axis.
+func (nsga3 *NSGA3) simplexVertex(referencePoint ReferencePoint, i int) ([]float64, bool) {
+	numberOfObjectiveFunctions := len(referencePoint.Coordinates)
+	gap := 1.0 / float64(nsga3.NumberOfSegments)
+	coordinates := make([]float64, numberOfObjectiveFunctions)
+	for j, coordinate := range referencePoint.Coordinates {
+		offset := -1.0 / float64(numberOfObjectiveFunctions)
+		if j == i {
+			offset += 1
+		}
+		coordinates[j] = coordinate + gap*offset
+		if coordinates[j] < -math.Pow(10, -9) {
+			return nil, false
+		}
+		coordinates[j] = math.Max(coordinates[j], 0)
+	}
+	return coordinates, true
+}
+
+func (nsga3 *NSGA3) hasReferencePointAt(coordinates []float64) bool {
+	for _, referencePoint := range nsga3.referencePoints {
+		if euclideanDistance(referencePoint.Coordinates, coordinates) < math.Pow(10, -9) {
+			return true
+		}
+	}
+	return false
+}
+
+// computeNicheCounts returns the number of individuals of the population associated with
+// every reference point by reference point ID, without modifying the reference points.
+func (nsga3 *NSGA3) computeNicheCounts(population Population, referencePoints []*ReferencePoint) map[string]int {
+	nsga3.normalize(population)
+	Associate(population, referencePoints)
+	nicheCounts := make(map[string]int, len(referencePoints))
+	for _, referencePoint := range referencePoints {
+		nicheCounts[referencePoint.ID] = 0
+	}
+	for _, individual := range population {
+		if _, exists := nicheCounts[individual.ReferencePoint.ID]; exists {
+			nicheCounts[individual.ReferencePoint.ID]++
+		}
+	}
+	return nicheCounts
+}
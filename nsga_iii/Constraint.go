@@ -0,0 +1,125 @@
+package nsga_iii
+
+import (
+	"math"
+	"strings"
+)
+
+// Constraint is a hard constraint on an individual. Violation returns zero when
+// the constraint is satisfied and a positive magnitude otherwise.
+type Constraint interface {
+	Name() string
+	Violation(individual *Individual) float64
+}
+
+const (
+	ResourceCapacityConstraintName = "resource-capacity"
+)
+
+type constraintFunction struct {
+	name      string
+	violation func(individual *Individual) float64
+}
+
+func (constraint constraintFunction) Name() string {
+	return constraint.name
+}
+
+func (constraint constraintFunction) Violation(individual *Individual) float64 {
+	return constraint.violation(individual)
+}
+
+// NewConstraint wraps a violation function into a Constraint.
+func NewConstraint(name string, violation func(individual *Individual) float64) Constraint {
+	return constraintFunction{name: name, violation: violation}
+}
+
+// ResourceCapacityConstraint is violated by the amount of resources requested
+// beyond the available resources of each node. Resources restricts the constraint
+// to the given dimensions; every dimension required by the tasks is checked when empty.
+type ResourceCapacityConstraint struct {
+	Resources []string
+}
+
+func (constraint ResourceCapacityConstraint) Name() string {
+	if len(constraint.Resources) == 0 {
+		return ResourceCapacityConstraintName
+	}
+	return ResourceCapacityConstraintName + ":" + strings.Join(constraint.Resources, ",")
+}
+
+func (constraint ResourceCapacityConstraint) Violation(individual *Individual) float64 {
+	problem := individual.Problem
+	var resourceIndexes []int
+	if len(constraint.Resources) == 0 {
+		for r := range problem.ResourceNames {
+			resourceIndexes = append(resourceIndexes, r)
+		}
+	}
+	for _, name := range constraint.Resources {
+		// dimensions no task requires cannot be exceeded
+		if resourceIndex, exists := problem.ResourceIndex(name); exists {
+			resourceIndexes = append(resourceIndexes, resourceIndex)
+		}
+	}
+
+	violation := 0.0
+	for n := range problem.Nodes {
+		remainingResources := individual.RemainingResourcesOfNode(n)
+		for _, r := range resourceIndexes {
+			if remaining := remainingResources[r]; remaining < 0 {
+				violation += math.Abs(remaining)
+			}
+		}
+	}
+	return violation
+}
+
+// DefaultConstraints are the constraints enforced when GeneticAlgorithm.Constraints is empty.
+func DefaultConstraints() []Constraint {
+	return []Constraint{ResourceCapacityConstraint{}, NodeAffinityConstraint{}, TaskAffinityConstraint{}}
+}
+
+// AddConstraint registers a hard constraint in addition to the constraints
+// already enforced by the genetic algorithm.
+func (g *GeneticAlgorithm) AddConstraint(constraint Constraint) {
+	if len(g.Constraints) == 0 {
+		g.Constraints = DefaultConstraints()
+	}
+	g.Constraints = append(g.Constraints, constraint)
+}
+
+func (g GeneticAlgorithm) constraints() []Constraint {
+	constraints := g.Constraints
+	if len(constraints) == 0 {
+		constraints = DefaultConstraints()
+	}
+	if g.Rescheduling.Enabled {
+		constraints = g.reschedulingConstraints(constraints)
+	}
+	return constraints
+}
+
+// normalizeConstraintViolations rescales the violation of every constraint by its
+// largest violation in the population, so constraints measured in different units
+// contribute equally to ConstrainedViolationValue.
+func normalizeConstraintViolations(population Population) {
+	if len(population) == 0 {
+		return
+	}
+	maximumViolations := make([]float64, len(population[0].ConstraintViolations))
+	for _, individual := range population {
+		for i, violation := range individual.ConstraintViolations {
+			maximumViolations[i] = math.Max(maximumViolations[i], violation)
+		}
+	}
+
+	for _, individual := range population {
+		individual.ConstrainedViolationValue = 0
+		for i, violation := range individual.ConstraintViolations {
+			if maximumViolations[i] > 0 {
+				individual.ConstrainedViolationValue += violation / maximumViolations[i]
+			}
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package nsga_iii
+
+import (
+	"math/rand"
+)
+
+// Crossover recombines the genomes of two parents into the genome of a child. The
+// parent genomes must not be modified.
+type Crossover interface {
+	Recombine(problem *Problem, firstGenome []int32, secondGenome []int32, random *rand.Rand) []int32
+}
+
+// SinglePointCrossover takes the genes of the tasks before a random cut from the first
+// parent and the others from the second parent.
+type SinglePointCrossover struct{}
+
+func (crossover SinglePointCrossover) Recombine(problem *Problem, firstGenome []int32, secondGenome []int32, random *rand.Rand) []int32 {
+	newGenome := make([]int32, len(firstGenome))
+	randomCut := random.Intn(len(firstGenome))
+
+	copy(newGenome[:randomCut], firstGenome[:randomCut])
+	copy(newGenome[randomCut:], secondGenome[randomCut:])
+	return newGenome
+}
+
+// TwoPointCrossover takes the genes of the tasks between two random cuts from the second
+// parent and the others from the first parent.
+type TwoPointCrossover struct{}
+
+func (crossover TwoPointCrossover) Recombine(problem *Problem, firstGenome []int32, secondGenome []int32, random *rand.Rand) []int32 {
+	newGenome := append([]int32{}, firstGenome...)
+	firstCut := random.Intn(len(firstGenome) + 1)
+	secondCut := random.Intn(len(firstGenome) + 1)
+	if firstCut > secondCut {
+		firstCut, secondCut = secondCut, firstCut
+	}
+	copy(newGenome[firstCut:secondCut], secondGenome[firstCut:secondCut])
+	return newGenome
+}
+
+// UniformCrossover takes the gene of every task from either parent with equal probability,
+// so the child does not depend on the order of the tasks.
+type UniformCrossover struct{}
+
+func (crossover UniformCrossover) Recombine(problem *Problem, firstGenome []int32, secondGenome []int32, random *rand.Rand) []int32 {
+	newGenome := make([]int32, len(firstGenome))
+	for t := range newGenome {
+		newGenome[t] = firstGenome[t]
+		if random.Intn(2) == 1 {
+			newGenome[t] = secondGenome[t]
+		}
+	}
+	return newGenome
+}
+
+// NodeGroupCrossover inherits the whole task set of every node of a random half of the
+// nodes from the first parent. The other tasks keep their node in the second parent unless
+// it is one of the inherited nodes, in which case they keep their node in the first parent,
+// so the inherited nodes hold exactly the tasks they hold in the first parent.
+type NodeGroupCrossover struct{}
+
+func (crossover NodeGroupCrossover) Recombine(problem *Problem, firstGenome []int32, secondGenome []int32, random *rand.Rand) []int32 {
+	isInherited := make([]bool, len(problem.Nodes))
+	for n := range isInherited {
+		isInherited[n] = random.Intn(2) == 1
+	}
+
+	newGenome := make([]int32, len(firstGenome))
+	for t := range newGenome {
+		if firstGenome[t] != Unassigned && isInherited[firstGenome[t]] {
+			newGenome[t] = firstGenome[t]
+		} else if secondGenome[t] == Unassigned || !isInherited[secondGenome[t]] {
+			newGenome[t] = secondGenome[t]
+		} else {
+			newGenome[t] = firstGenome[t]
+		}
+	}
+	return newGenome
+}
+
+// TaskTypeCrossover inherits the genes of all the tasks of a TaskType from the same
+// parent, chosen with equal probability for every TaskType, so that the replicas of a
+// TaskType keep their spread and affinities.
+type TaskTypeCrossover struct{}
+
+func (crossover TaskTypeCrossover) Recombine(problem *Problem, firstGenome []int32, secondGenome []int32, random *rand.Rand) []int32 {
+	isFromSecondParent := make([]bool, len(problem.taskTypeIndexByTaskType))
+	for i := range isFromSecondParent {
+		isFromSecondParent[i] = random.Intn(2) == 1
+	}
+
+	newGenome := make([]int32, len(firstGenome))
+	for t := range newGenome {
+		newGenome[t] = firstGenome[t]
+		if isFromSecondParent[problem.taskTypeIndexes[t]] {
+			newGenome[t] = secondGenome[t]
+		}
+	}
+	return newGenome
+}
+
+func (g GeneticAlgorithm) crossover() Crossover {
+	if g.Crossover == nil {
+		return SinglePointCrossover{}
+	}
+	return g.Crossover
+}
+
+// isCrossedOver reports whether the parents of a child are recombined, or the child is a
+// copy of the first parent.
+func (g GeneticAlgorithm) isCrossedOver() bool {
+	if g.CrossoverProbability <= 0 || g.CrossoverProbability >= 1 {
+		return true
+	}
+	return g.random().Float64() < g.CrossoverProbability
+}
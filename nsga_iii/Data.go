@@ -7,11 +7,16 @@ import (
 type Resources struct {
 	CpuCores float64
 	Memory   float64
+	// Extended holds any other resource dimension by name, e.g. ResourceEphemeralStorage.
+	Extended map[string]float64
 }
 
 type Power struct {
 	IdlePower float64
 	MaxPower  float64
+	// ResourceWeights weights the utilization of each resource dimension in the power model.
+	// Only memory utilization is considered when it is empty.
+	ResourceWeights map[string]float64
 }
 
 type Task struct {
@@ -19,14 +24,18 @@ type Task struct {
 	RequiredResources Resources
 	NodeID            string
 	TaskType          string
+	Labels            map[string]string
+	NodeAffinity      NodeAffinity
+	TaskAffinity      []TaskAffinityTerm
+	TaskAntiAffinity  []TaskAffinityTerm
 }
 
 type Node struct {
 	ID                 string
 	AvailableResources Resources
-	RemainingResources *Resources
-	Tasks              map[string]Task
 	Power              Power
+	Labels             map[string]string
+	Topology           Topology
 
 	CpuWeight float64
 	MemoryWeight float64
@@ -36,13 +45,12 @@ type Node struct {
 
 //for Genetic Algorithm
 type Individual struct {
-	ID                               string
-	NodeIdOfTaskIdAssignment         map[string]string
-	NodeIdOfTaskIdOriginalAssignment map[string]string
-	AllNodes                         map[string]Node
-	AllTasks                         map[string]Task
-	NumberOfUnassignedTasks          int
-	NumberOfUnlessNodes              int
+	ID                      string
+	Problem                 *Problem
+	// Genome holds the index of the node of every task of the problem, or Unassigned.
+	Genome                  []int32
+	NumberOfUnassignedTasks int
+	NumberOfUnlessNodes     int
 
 			ObjectiveValues           []float64
 	TranslatedObjectiveValues []float64
@@ -56,7 +64,17 @@ type Individual struct {
 	Rank                            int
 	CrowdingDistance                float64
 
+	// remainingResources is stored node-major like Problem.capacities, and the tasks of
+	// node n are tasksByNode[taskOffsets[n]:taskOffsets[n+1]]
+	remainingResources []float64
+	taskOffsets        []int32
+	tasksByNode        []int32
+	// mutation is one plus the index of the mutation operator applied to the individual
+	// when it was created, or zero if it was not mutated
+	mutation           int
+
 	//NSGA III
+	ConstraintViolations      []float64
 	ConstrainedViolationValue float64
 	IsFeasible bool
 }
@@ -67,24 +85,33 @@ type ReferencePoint struct{
 	NicheCount int
 }
 
-func (individual *Individual) init(originalNodes []Node, originalTasks []Task) {
-	individual.AllNodes = make(map[string]Node)
-	for _, node := range originalNodes {
-		node.RemainingResources = &Resources{}
-		individual.AllNodes[node.ID] = node
-	}
-
-	individual.AllTasks = make(map[string]Task)
-	for _, task := range originalTasks {
-		individual.AllTasks[task.TaskID] = task
-	}
+// NodeIdOfTaskIdAssignment returns the node ID of every task ID, or the empty node ID
+// for unassigned tasks.
+func (individual *Individual) NodeIdOfTaskIdAssignment() map[string]string {
+	return individual.Problem.AssignmentOf(individual.Genome)
+}
 
-	individual.ComputeValues()
-	individual.TranslatedObjectiveValues = make([]float64, len(individual.ObjectiveValues))
-	individual.NormalizedObjectiveValues = make([]float64, len(individual.ObjectiveValues))
+// TasksOfNode returns the indexes of the tasks assigned to the node, in task order.
+// The returned slice must not be modified.
+func (individual *Individual) TasksOfNode(nodeIndex int) []int32 {
+	return individual.tasksByNode[individual.taskOffsets[nodeIndex]:individual.taskOffsets[nodeIndex+1]]
+}
 
+// RemainingResourcesOfNode returns the resources of the node left by its tasks, indexed
+// by Problem.ResourceNames. The returned slice must not be modified.
+func (individual *Individual) RemainingResourcesOfNode(nodeIndex int) []float64 {
+	numberOfResources := len(individual.Problem.ResourceNames)
+	return individual.remainingResources[nodeIndex*numberOfResources : (nodeIndex+1)*numberOfResources]
 }
 
+// RemainingResource returns the named resource of the node left by its tasks.
+func (individual *Individual) RemainingResource(nodeIndex int, name string) float64 {
+	resourceIndex, exists := individual.Problem.ResourceIndex(name)
+	if !exists {
+		return individual.Problem.Nodes[nodeIndex].AvailableResources.Get(name)
+	}
+	return individual.RemainingResourcesOfNode(nodeIndex)[resourceIndex]
+}
 
 func (individual *Individual) ComputeValues(){
 	individual.computeRemainingResources()
@@ -95,49 +122,60 @@ func (individual *Individual) ComputeValues(){
 	individual.IsFeasible = individual.CheckIsFeasible()
 }
 func (individual *Individual) computeRemainingResources() {
+	problem := individual.Problem
+	numberOfResources := len(problem.ResourceNames)
+	if individual.remainingResources == nil {
+		individual.remainingResources = make([]float64, len(problem.capacities))
+		individual.taskOffsets = make([]int32, len(problem.Nodes)+1)
+		individual.tasksByNode = make([]int32, len(problem.Tasks))
+	}
+	copy(individual.remainingResources, problem.capacities)
+	for n := range individual.taskOffsets {
+		individual.taskOffsets[n] = 0
+	}
 
-	for _, node := range individual.AllNodes{
-		for task := range node.Tasks{
-			delete(node.Tasks, task)
+	for t, nodeIndex := range individual.Genome {
+		if nodeIndex == Unassigned {
+			continue
+		}
+		individual.taskOffsets[nodeIndex+1]++
+		remainingResourcesOfNode := individual.remainingResources[int(nodeIndex)*numberOfResources:]
+		for r, demand := range problem.DemandsOfTask(t) {
+			remainingResourcesOfNode[r] -= demand
 		}
-		node.RemainingResources.Memory = node.AvailableResources.Memory
-		node.RemainingResources.CpuCores = node.AvailableResources.CpuCores
 	}
 
-	counter := 0
-	for taskID, _ := range individual.NodeIdOfTaskIdAssignment {
-		counter++
-		nodeID := individual.NodeIdOfTaskIdAssignment[taskID]
-		if len(nodeID) != 0 {
-			task := individual.AllTasks[taskID]
-			node := individual.AllNodes[nodeID]
-			task.NodeID = node.ID
-			if len(node.Tasks) == 0 {
-				node.Tasks = make(map[string]Task)
-			}
-			node.Tasks[task.TaskID] = task
-			node.RemainingResources.CpuCores = node.RemainingResources.CpuCores - task.RequiredResources.CpuCores
-			node.RemainingResources.Memory = node.RemainingResources.Memory - task.RequiredResources.Memory
-
-			individual.AllTasks[taskID] = task
-			individual.AllNodes[nodeID] = node
+	// taskOffsets[n+1] holds the number of tasks of node n, and the prefix sum turns
+	// taskOffsets[n] into the start of the tasks of node n. The scatter uses taskOffsets[n]
+	// as the insertion cursor of node n, which leaves it at the end of the tasks of node n,
+	// so the offsets are shifted back by one node afterwards
+	for n := 1; n < len(individual.taskOffsets); n++ {
+		individual.taskOffsets[n] += individual.taskOffsets[n-1]
+	}
+	for t, nodeIndex := range individual.Genome {
+		if nodeIndex != Unassigned {
+			individual.tasksByNode[individual.taskOffsets[nodeIndex]] = int32(t)
+			individual.taskOffsets[nodeIndex]++
 		}
 	}
-
+	for n := len(individual.taskOffsets) - 1; n > 0; n-- {
+		individual.taskOffsets[n] = individual.taskOffsets[n-1]
+	}
+	individual.taskOffsets[0] = 0
 }
 
 func (individual *Individual) computeNumberOfUselessNodes(){
 	individual.NumberOfUnlessNodes = 0
-	for _, node := range individual.AllNodes{
-		if len(node.Tasks) == 0{
+	for n := range individual.Problem.Nodes {
+		if len(individual.TasksOfNode(n)) == 0{
 			individual.NumberOfUnlessNodes++
 		}
 	}
 }
 func (individual *Individual) computeUnassignedTasks() {
 	individual.NumberOfUnassignedTasks = 0
-	for _, nodeID := range individual.NodeIdOfTaskIdAssignment {
-		if len(nodeID) == 0 {
+	for _, nodeIndex := range individual.Genome {
+		if nodeIndex == Unassigned {
 			//this task is not assigned to node
 			individual.NumberOfUnassignedTasks++
 		}
@@ -145,19 +183,23 @@ func (individual *Individual) computeUnassignedTasks() {
 }
 
 func (individual *Individual) computeObjectiveFunctions() {
-	individual.ObjectiveValues = []float64{}
-	individual.ObjectiveValues = append(individual.ObjectiveValues, float64(individual.computeSpreadObjectiveFunction()))
-	individual.ObjectiveValues = append(individual.ObjectiveValues, float64(individual.computeUniquenessObjectiveFunction()))
-	individual.ObjectiveValues = append(individual.ObjectiveValues, individual.computePowerObjectiveFunction())
-	individual.ObjectiveValues = append(individual.ObjectiveValues, individual.computeResourcesUtilizationObjectiveFunction())
+	objectives := individual.Problem.Objectives
+	individual.ObjectiveValues = make([]float64, len(objectives))
+	for i, objective := range objectives {
+		objectiveValue := objective.Evaluate(individual)
+		if objective.Direction() == Maximize {
+			objectiveValue = -objectiveValue
+		}
+		individual.ObjectiveValues[i] = objectiveValue
+	}
 }
 
 func (individual *Individual) computeSpreadObjectiveFunction() int {
 	spreadObjectiveValue := 0
 
-	for _, node := range individual.AllNodes {
+	for n := range individual.Problem.Nodes {
 		nodeSpreadObjectiveValue := 0
-		for i := 0; i < len(node.Tasks); i++ {
+		for i := 0; i < len(individual.TasksOfNode(n)); i++ {
 			nodeSpreadObjectiveValue += i + 1
 		}
 		spreadObjectiveValue += nodeSpreadObjectiveValue
@@ -167,40 +209,60 @@ func (individual *Individual) computeSpreadObjectiveFunction() int {
 }
 
 func (individual *Individual) computeUniquenessObjectiveFunction() int {
-	totalUniquenessObjectiveValue := 0
-	for _, node := range individual.AllNodes {
-		replicasOfTaskType := make(map[string]int)
-		for _, task := range node.Tasks {
-			replicasOfTaskType[task.TaskType] += 1
-		}
-		nodeUniquenessObjectiveValue := 0
-		for _, replicas := range replicasOfTaskType {
-			for i := 0; i < replicas; i++ {
-				nodeUniquenessObjectiveValue += i + 1
-			}
-		}
-		totalUniquenessObjectiveValue += nodeUniquenessObjectiveValue
-	}
-	return totalUniquenessObjectiveValue
+	return individual.computeTopologyUniquenessObjectiveFunction(TopologyKeyNode)
 }
 
 func (individual *Individual) computePowerObjectiveFunction() float64 {
 	totalPower := 0.0
-	for _, node := range individual.AllNodes {
-		totalPower += (node.Power.MaxPower -node.Power.IdlePower)*
-			((node.AvailableResources.Memory -node.RemainingResources.Memory)/
-				node.AvailableResources.Memory) + node.Power.IdlePower
+	for n, node := range individual.Problem.Nodes {
+		totalPower += (node.Power.MaxPower -node.Power.IdlePower)*individual.computePowerUtilization(n) + node.Power.IdlePower
 	}
 	return totalPower
 }
 
+// computePowerUtilization returns the weighted average utilization of the resource
+// dimensions listed in Power.ResourceWeights of the node, or its memory utilization if there are none.
+func (individual *Individual) computePowerUtilization(nodeIndex int) float64 {
+	node := individual.Problem.Nodes[nodeIndex]
+	return powerUtilizationOfNode(node, func(name string) float64 {
+		return node.AvailableResources.Get(name) - individual.RemainingResource(nodeIndex, name)
+	})
+}
+
+// powerUtilizationOfNode returns the weighted average share of the resource dimensions listed
+// in Power.ResourceWeights of the node taken by usedResource, or the share of its memory if
+// there are none. Dimensions the node has none of are left out.
+func powerUtilizationOfNode(node Node, usedResource func(name string) float64) float64 {
+	if len(node.Power.ResourceWeights) == 0 {
+		if node.AvailableResources.Memory <= 0 {
+			return 0
+		}
+		return usedResource(ResourceMemory) / node.AvailableResources.Memory
+	}
+	utilization := 0.0
+	totalWeight := 0.0
+	for _, name := range sortedExtendedResourceNames(node.Power.ResourceWeights) {
+		available := node.AvailableResources.Get(name)
+		if available <= 0 {
+			continue
+		}
+		weight := node.Power.ResourceWeights[name]
+		utilization += weight * usedResource(name) / available
+		totalWeight += weight
+	}
+	if totalWeight == 0 {
+		return 0
+	}
+	return utilization / totalWeight
+}
+
 func (individual *Individual) computeAssignmentDifferenceObjectiveFunction() int {
-	if len(individual.NodeIdOfTaskIdOriginalAssignment) == 0 {
+	if !individual.Problem.hasOriginalAssignment {
 		return 0
 	}
 	differenceObjectiveValue := 0
-	for taskIdOriginal, nodeIdOriginal := range individual.NodeIdOfTaskIdOriginalAssignment {
-		if individual.NodeIdOfTaskIdAssignment[taskIdOriginal] != nodeIdOriginal {
+	for t, originalNodeIndex := range individual.Problem.OriginalAssignment {
+		if individual.Genome[t] != originalNodeIndex {
 			differenceObjectiveValue++
 		}
 	}
@@ -210,29 +272,44 @@ func (individual *Individual) computeAssignmentDifferenceObjectiveFunction() int
 
 func (individual *Individual) computeMemoryUtilizationObjectiveFunction() float64 {
 	totalOverResourceUtilization := 0.0
-	for _, node := range individual.AllNodes {
-		if node.RemainingResources.Memory > 0 {
-			totalOverResourceUtilization += math.Abs(node.RemainingResources.Memory)
+	for n := range individual.Problem.Nodes {
+		if remainingMemory := individual.RemainingResource(n, ResourceMemory); remainingMemory > 0 {
+			totalOverResourceUtilization += math.Abs(remainingMemory)
 		}
 	}
 	return totalOverResourceUtilization
 }
 
+// computeResourcesUtilizationObjectiveFunction averages, over the nodes, the difference
+// between the most and the least remaining ratio of the resource dimensions required by the tasks.
 func (individual *Individual) computeResourcesUtilizationObjectiveFunction() float64{
+	problem := individual.Problem
 	resourcesUtilizationObjectiveValue := 0.0
-	for _, node := range individual.AllNodes{
-		resourcesUtilizationObjectiveValue += math.Abs((node.RemainingResources.CpuCores/node.AvailableResources.CpuCores)-
-			(node.RemainingResources.Memory/node.AvailableResources.Memory))
+	for n := range problem.Nodes {
+		capacities := problem.CapacitiesOfNode(n)
+		minimumRemainingRatio := math.MaxFloat64
+		maximumRemainingRatio := -math.MaxFloat64
+		for r, remaining := range individual.RemainingResourcesOfNode(n) {
+			if capacities[r] <= 0 {
+				continue
+			}
+			remainingRatio := remaining / capacities[r]
+			minimumRemainingRatio = math.Min(minimumRemainingRatio, remainingRatio)
+			maximumRemainingRatio = math.Max(maximumRemainingRatio, remainingRatio)
+		}
+		if maximumRemainingRatio >= minimumRemainingRatio {
+			resourcesUtilizationObjectiveValue += maximumRemainingRatio - minimumRemainingRatio
+		}
 	}
-	return resourcesUtilizationObjectiveValue/float64(len(individual.AllNodes))
+	return resourcesUtilizationObjectiveValue/float64(len(problem.Nodes))
 }
 
 
 func (individual *Individual) computeCPUUtilizationObjectiveFunction() float64 {
 	totalOverResourceUtilization := 0.0
-	for _, node := range individual.AllNodes {
-		if node.RemainingResources.CpuCores > 0 {
-			totalOverResourceUtilization += math.Abs(node.RemainingResources.CpuCores)
+	for n := range individual.Problem.Nodes {
+		if remainingCpuCores := individual.RemainingResource(n, ResourceCpuCores); remainingCpuCores > 0 {
+			totalOverResourceUtilization += math.Abs(remainingCpuCores)
 		}
 	}
 	return totalOverResourceUtilization
@@ -240,8 +317,8 @@ func (individual *Individual) computeCPUUtilizationObjectiveFunction() float64 {
 
 func (individual *Individual) computeResourceUtilizationObjectiveFunction2() float64 {
 	totalOverResourceUtilization := 0.0
-	for _, node := range individual.AllNodes {
-		totalOverResourceUtilization += math.Abs(node.RemainingResources.Memory)
+	for n := range individual.Problem.Nodes {
+		totalOverResourceUtilization += math.Abs(individual.RemainingResource(n, ResourceMemory))
 	}
 	return totalOverResourceUtilization
 }
@@ -271,8 +348,8 @@ func (individual *Individual) crowdedComparisonOperatorLess(anotherIndividual In
 //---------------
 
 func (individual *Individual) CheckIsFeasible() bool{
-	for _, node := range individual.AllNodes{
-		if node.RemainingResources.Memory < 0 || node.RemainingResources.CpuCores < 0{
+	for _, violation := range individual.ConstraintViolations {
+		if violation > 0 {
 			return false
 		}
 	}
@@ -281,25 +358,40 @@ func (individual *Individual) CheckIsFeasible() bool{
 }
 
 func (individual *Individual) ComputeConstrainedViolationValue()float64{
+	constraints := individual.Problem.Constraints
+	individual.ConstraintViolations = make([]float64, len(constraints))
 	constrainedViolationValue := 0.0
-	for _, node := range individual.AllNodes{
-		if node.RemainingResources.Memory < 0 {
-			constrainedViolationValue += math.Abs(node.RemainingResources.Memory)
-		}
-
-		if node.RemainingResources.CpuCores < 0 {
-			constrainedViolationValue += math.Abs(node.RemainingResources.CpuCores)
-		}
+	for i, constraint := range constraints {
+		individual.ConstraintViolations[i] = constraint.Violation(individual)
+		constrainedViolationValue += individual.ConstraintViolations[i]
 	}
 	return constrainedViolationValue
 }
 
+// compareConstraintViolation returns a negative number when the individual is better
+// than the other one with respect to the constraints, a positive number when it is worse
+// and zero when neither is preferred.
+func (individual *Individual) compareConstraintViolation(anotherIndividual Individual) int {
+	if individual.IsFeasible && anotherIndividual.IsFeasible {
+		return 0
+	} else if individual.IsFeasible && !anotherIndividual.IsFeasible {
+		return -1
+	} else if !individual.IsFeasible && anotherIndividual.IsFeasible {
+		return 1
+	} else if individual.ConstrainedViolationValue < anotherIndividual.ConstrainedViolationValue {
+		return -1
+	} else if individual.ConstrainedViolationValue > anotherIndividual.ConstrainedViolationValue {
+		return 1
+	}
+	return 0
+}
+
 func (individual *Individual) constraintDominate(anotherIndividual Individual)bool {
-	if (individual.IsFeasible && !anotherIndividual.IsFeasible) ||
-		(!individual.IsFeasible && !anotherIndividual.IsFeasible && individual.ConstrainedViolationValue < anotherIndividual.ConstrainedViolationValue) ||
-		(individual.IsFeasible && anotherIndividual.IsFeasible && individual.dominates(anotherIndividual)) {
+	comparison := individual.compareConstraintViolation(anotherIndividual)
+	if comparison < 0 ||
+		(comparison == 0 && individual.IsFeasible && anotherIndividual.IsFeasible && individual.dominates(anotherIndividual)) {
 		return true
 	} else {
 		return false
 	}
-}
\ No newline at end of file
+}
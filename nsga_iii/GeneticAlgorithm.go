@@ -2,10 +2,8 @@ package nsga_iii
 
 import (
 	"math/rand"
-	"github.com/rs/xid"
 	"fmt"
 	"math"
-	"time"
 )
 
 type GeneticAlgorithm struct {
@@ -14,69 +12,122 @@ type GeneticAlgorithm struct {
 	NodeIdOfTaskIdOriginalAssignment map[string]string
 	PopulationSize                   int
 	NumberOfGenerations              int
+
+	// Objectives optimized by the run, in order. DefaultObjectiveNames are used when empty.
+	Objectives        []Objective
+	ObjectiveRegistry ObjectiveRegistry
+
+	// Hard constraints of the run. DefaultConstraints are enforced when empty.
+	Constraints []Constraint
+	// NormalizeConstraintViolations scales each constraint violation by its maximum in
+	// the population before summing them into ConstrainedViolationValue.
+	NormalizeConstraintViolations bool
+
+	Rescheduling ReschedulingOptions
+
+	// Seed makes runs reproducible when it is not zero. Random, if given, is used as the
+	// random source instead. Neither is safe for use by concurrent runs.
+	Seed          int64
+	Random        *rand.Rand
+	individualIDs *idSequence
+
+	// Workers is the number of goroutines creating and evaluating individuals, one if not positive.
+	Workers int
+
+	// NonDominatedSorter sorts the populations into fronts, FastNonDominatedSorter if nil.
+	NonDominatedSorter NonDominatedSorter
+
+	// Crossover recombines the parents, SinglePointCrossover if nil. CrossoverProbability is
+	// the probability of recombining the parents of a child instead of copying the first
+	// parent, 1 if not positive.
+	Crossover            Crossover
+	CrossoverProbability float64
+	// Mutations are drawn by weight to mutate an offspring with probability MutationProbability,
+	// DefaultMutations and 0.5 if empty or not positive. AdaptiveMutation shifts the weights
+	// towards the operators whose offspring recently reached the first front.
+	Mutations           []WeightedMutation
+	MutationProbability float64
+	AdaptiveMutation    bool
+	mutationWeights     *mutationWeights
+	// Repair, if given, restores the feasibility of infeasible offspring.
+	Repair Repair
+	// SeedingStrategies build their Ratio of the initial population after the rescheduling
+	// seeds, and random first-fit placements build the rest.
+	SeedingStrategies []SeedingRatio
+
+	LocalSearch LocalSearchOptions
+
+	problem *Problem
 }
 
 type Population []*Individual
 
 func (g GeneticAlgorithm) GenerateRandomFeasibleIndividual() *Individual {
-	nodes := make([]Node, len(g.AllNodes))
-	for i, node := range g.AllNodes {
-		nodes[i] = Node{RemainingResources: &Resources{Memory: node.AvailableResources.Memory, CpuCores: node.AvailableResources.CpuCores}, ID: node.ID}
-	}
+	g = g.withProblem()
+	placement := newPlacement(g.problem)
 
-	shuffleNodes(nodes)
-
-	guid := xid.New()
-	nodeIdOfTaskIdAssignment := make(map[string]string)
-	for _, task := range g.AllTasks {
-		for _, node := range nodes {
-			if task.RequiredResources.CpuCores <= node.RemainingResources.CpuCores &&
-				task.RequiredResources.Memory <= node.RemainingResources.Memory {
-				nodeIdOfTaskIdAssignment[task.TaskID] = node.ID
-				node.RemainingResources.Memory -= task.RequiredResources.Memory
-				node.RemainingResources.CpuCores -= task.RequiredResources.CpuCores
-				break
-			}
-		}
-	}
+	nodes := placement.shuffledNodes(g.random())
 
-	newIndividual := Individual{ID: guid.String(), NodeIdOfTaskIdAssignment: nodeIdOfTaskIdAssignment, NodeIdOfTaskIdOriginalAssignment: g.NodeIdOfTaskIdOriginalAssignment}
+	for t := range g.problem.Tasks {
+		placement.placeFirstFit(t, nodes)
+	}
 
-	newIndividual.init(g.AllNodes, g.AllTasks)
+	return g.newIndividual(placement.genome)
+}
 
-	return &newIndividual
+func (g GeneticAlgorithm) newIndividual(genome []int32) *Individual {
+	return g.problem.NewIndividual(g.nextIndividualID(), genome)
+}
 
+// withProblem returns a copy of the genetic algorithm holding the problem shared by the
+// individuals of a run, unless it already holds one.
+func (g GeneticAlgorithm) withProblem() GeneticAlgorithm {
+	if g.problem == nil {
+		g.problem = NewProblem(g.AllNodes, g.AllTasks, g.NodeIdOfTaskIdOriginalAssignment)
+		g.problem.Objectives = g.objectives()
+		g.problem.Constraints = g.constraints()
+	}
+	return g
 }
 
-func shuffleNodes(nodes []Node) {
-	rand.Seed(time.Now().UnixNano())
+func shuffleNodes(nodes []int32, random *rand.Rand) {
 	for len(nodes) > 0 {
 		n := len(nodes)
-		randIndex := rand.Intn(n)
+		randIndex := random.Intn(n)
 		nodes[n-1], nodes[randIndex] = nodes[randIndex], nodes[n-1]
 		nodes = nodes[:n-1]
 	}
 }
 
 func (g GeneticAlgorithm) GenerateRandomFeasiblePopulation() Population {
-	randomPopulation := make([]*Individual, g.PopulationSize)
-	for i := 0; i < g.PopulationSize; i++ {
-		randomPopulation[i] = g.GenerateRandomFeasibleIndividual()
+	g = g.withRandomSource().withProblem()
+	numberOfReschedulingSeeds := g.numberOfReschedulingSeeds()
+	maximumSeedMoves := g.Rescheduling.MaximumSeedMoves
+	if maximumSeedMoves <= 0 {
+		maximumSeedMoves = defaultMaximumSeedMoves
 	}
-	return randomPopulation
+	seedingStrategies := g.seedingStrategiesOfIndividuals(numberOfReschedulingSeeds)
+	return g.generateConcurrently(g.PopulationSize, func(g GeneticAlgorithm, i int) *Individual {
+		if i == 0 && numberOfReschedulingSeeds > 0 {
+			return g.generateIndividualFromOriginalAssignment(0)
+		} else if i < numberOfReschedulingSeeds {
+			return g.generateIndividualFromOriginalAssignment(1 + g.random().Intn(maximumSeedMoves))
+		} else if seedingStrategies[i] != nil {
+			return g.newIndividual(seedingStrategies[i].Genome(g.problem, g.random()))
+		}
+		return g.GenerateRandomFeasibleIndividual()
+	})
 }
 func (g GeneticAlgorithm) generateRandomIndividual() *Individual {
-	guid := xid.New()
-	nodeIdOfTaskIdAssignment := make(map[string]string)
-	for _, task := range g.AllTasks {
-		nodeIdOfTaskIdAssignment[task.TaskID] = g.AllNodes[rand.Intn(len(g.AllNodes))].ID
+	genome := make([]int32, len(g.problem.Tasks))
+	for t := range genome {
+		genome[t] = int32(g.random().Intn(len(g.problem.Nodes)))
 	}
-	newIndividual := Individual{ID: guid.String(), NodeIdOfTaskIdAssignment: nodeIdOfTaskIdAssignment, NodeIdOfTaskIdOriginalAssignment: g.NodeIdOfTaskIdOriginalAssignment}
-	newIndividual.init(g.AllNodes, g.AllTasks)
-	return &newIndividual
+	return g.newIndividual(genome)
 }
 
 func (g GeneticAlgorithm) generateRandomPopulation() Population {
+	g = g.withProblem()
 	randomPopulation := make([]*Individual, g.PopulationSize)
 	for i := 0; i < g.PopulationSize; i++ {
 		randomPopulation[i] = g.generateRandomIndividual()
@@ -85,83 +136,18 @@ func (g GeneticAlgorithm) generateRandomPopulation() Population {
 }
 
 func (g GeneticAlgorithm) selectRandomIndividual(population Population) Individual {
-	return *population[rand.Intn(g.PopulationSize)]
+	return *population[g.random().Intn(g.PopulationSize)]
 }
 
 func (g GeneticAlgorithm) reproduce(firstIndividual Individual, secondIndividual Individual) Individual {
-	newNodeIdOfTaskIdAssignment := make(map[string]string)
-	numberOfTasks := len(firstIndividual.AllTasks)
-	randomCut := rand.Intn(numberOfTasks)
-
-	for i := 0; i < numberOfTasks; i++ {
-		task := g.AllTasks[i]
-		if i < randomCut {
-			newNodeIdOfTaskIdAssignment[task.TaskID] = firstIndividual.NodeIdOfTaskIdAssignment[task.TaskID]
-		} else {
-			newNodeIdOfTaskIdAssignment[task.TaskID] = secondIndividual.NodeIdOfTaskIdAssignment[task.TaskID]
-		}
-	}
-	guid := xid.New()
-	newIndividual := Individual{ID: guid.String(), NodeIdOfTaskIdAssignment: newNodeIdOfTaskIdAssignment, NodeIdOfTaskIdOriginalAssignment: g.NodeIdOfTaskIdOriginalAssignment}
-	newIndividual.init(g.AllNodes, g.AllTasks)
-	newIndividual.ComputeValues()
-	return newIndividual
-}
-
-func (g GeneticAlgorithm) mutate(individual *Individual) {
-	swap := func(individual *Individual) {
-		task1 := g.AllTasks[rand.Intn(len(g.AllTasks))]
-		task2 := g.AllTasks[rand.Intn(len(g.AllTasks))]
-
-		nodeID1 := individual.NodeIdOfTaskIdAssignment[task1.TaskID]
-		nodeID2 := individual.NodeIdOfTaskIdAssignment[task2.TaskID]
-		individual.NodeIdOfTaskIdAssignment[task1.TaskID] = nodeID2
-		individual.NodeIdOfTaskIdAssignment[task2.TaskID] = nodeID1
-	}
-	change := func(individual *Individual) {
-		task := g.AllTasks[rand.Intn(len(g.AllTasks))]
-		node := g.AllNodes[rand.Intn(len(g.AllNodes))]
-		individual.NodeIdOfTaskIdAssignment[task.TaskID] = node.ID
-	}
-	assignUnassigned := func(individual *Individual) {
-		var unassignedTasks []string
-		for taskID := range individual.NodeIdOfTaskIdAssignment {
-			if individual.NodeIdOfTaskIdAssignment[taskID] == "" {
-				unassignedTasks = append(unassignedTasks, taskID)
-			}
-		}
-		unassignedTaskID := unassignedTasks[rand.Intn(len(unassignedTasks))]
-		node := g.AllNodes[rand.Intn(len(g.AllNodes))]
-		individual.NodeIdOfTaskIdAssignment[unassignedTaskID] = node.ID
-	}
-	unassignAssigned := func(individual *Individual) {
-		var assignedTasks []string
-		for taskID := range individual.NodeIdOfTaskIdAssignment {
-			if individual.NodeIdOfTaskIdAssignment[taskID] != "" {
-				assignedTasks = append(assignedTasks, taskID)
-			}
-		}
-		assignedTaskID := assignedTasks[rand.Intn(len(assignedTasks))]
-		individual.NodeIdOfTaskIdAssignment[assignedTaskID] = ""
-
-	}
-
-	probability := rand.Float64()
-	if (probability <= 0.25) {
-		change(individual)
-	} else if (probability > 0.25 && probability <= 0.5) {
-		swap(individual)
-	} else if (probability > 0.5 && probability <= 0.51) {
-		if(!individual.IsFeasible) {
-			unassignAssigned(individual)
-		}
-	}else if(probability > 0.51 && probability <= 1.0){
-		if(individual.NumberOfUnassignedTasks > 0) {
-			assignUnassigned(individual)
-		}
+	var newGenome []int32
+	if g.isCrossedOver() {
+		newGenome = g.crossover().Recombine(firstIndividual.Problem, firstIndividual.Genome, secondIndividual.Genome, g.random())
+	} else {
+		newGenome = append([]int32{}, firstIndividual.Genome...)
 	}
-
-	individual.ComputeValues()
+	newIndividual := firstIndividual.Problem.NewIndividual(g.nextIndividualID(), newGenome)
+	return *newIndividual
 }
 
 func (g GeneticAlgorithm) combinePopulation(firstPopulation Population, secondPopulation Population) Population {
@@ -177,8 +163,8 @@ func (g GeneticAlgorithm) combinePopulation(firstPopulation Population, secondPo
 }
 
 func (g GeneticAlgorithm) binaryTormentSelection(population Population) Individual {
-	firstIndividual := population[rand.Intn(g.PopulationSize)]
-	secondIndividual := population[rand.Intn(g.PopulationSize)]
+	firstIndividual := population[g.random().Intn(g.PopulationSize)]
+	secondIndividual := population[g.random().Intn(g.PopulationSize)]
 
 	if firstIndividual.crowdedComparisonOperatorLess(*secondIndividual) {
 		return *firstIndividual
@@ -189,84 +175,74 @@ func (g GeneticAlgorithm) binaryTormentSelection(population Population) Individu
 
 //constrained nsga iii
 func (g GeneticAlgorithm) constrainedBinaryTournamentSelection(population Population) Individual {
-	firstIndividual := population[rand.Intn(g.PopulationSize/2)]
-	secondIndividual := population[g.PopulationSize/2+rand.Intn(g.PopulationSize/2)]
+	firstIndividual := population[g.random().Intn(g.PopulationSize/2)]
+	secondIndividual := population[g.PopulationSize/2+g.random().Intn(g.PopulationSize/2)]
 
-	if firstIndividual.IsFeasible && !secondIndividual.IsFeasible {
+	comparison := firstIndividual.compareConstraintViolation(*secondIndividual)
+	if comparison < 0 {
 		return *firstIndividual
-	} else if !firstIndividual.IsFeasible && secondIndividual.IsFeasible {
+	} else if comparison > 0 {
 		return *secondIndividual
-	} else if !firstIndividual.IsFeasible && !secondIndividual.IsFeasible {
-		if firstIndividual.ConstrainedViolationValue > secondIndividual.ConstrainedViolationValue {
-			return *secondIndividual
-		} else if firstIndividual.ConstrainedViolationValue < secondIndividual.ConstrainedViolationValue {
-			return *firstIndividual
-		} else {
-			if rand.Float64() > 0.5 {
-				return *firstIndividual
-			} else {
-				return *secondIndividual
-			}
-		}
+	} else if g.random().Float64() > 0.5 {
+		return *firstIndividual
 	} else {
-		if rand.Float64() > 0.5 {
-			return *firstIndividual
-		} else {
-			return *secondIndividual
-		}
+		return *secondIndividual
 	}
 }
 
 func (g GeneticAlgorithm) makeNewPopulation(parentPopulation Population, isInitial bool) Population {
-	newPopulation := make([]*Individual, g.PopulationSize)
-	var firstIndividual Individual
-	var secondIndividual Individual
-	for i := 0; i < g.PopulationSize; i++ {
-		firstIndividual = g.constrainedBinaryTournamentSelection(parentPopulation)
-		secondIndividual = g.constrainedBinaryTournamentSelection(parentPopulation)
-		newIndividual := g.reproduce(firstIndividual, secondIndividual)
+	return g.makeNewPopulationBySelection(parentPopulation, g.constrainedBinaryTournamentSelection)
+}
 
-		if rand.Float64()>0.5 {
-			g.mutate(&newIndividual)
-		}
-		newPopulation [i] = &newIndividual
+func (g GeneticAlgorithm) makeNewPopulationBySelection(parentPopulation Population, selection func(population Population) Individual) Population {
+	firstIndividuals := make([]Individual, g.PopulationSize)
+	secondIndividuals := make([]Individual, g.PopulationSize)
+	for i := 0; i < g.PopulationSize; i++ {
+		firstIndividuals[i] = selection(parentPopulation)
+		secondIndividuals[i] = selection(parentPopulation)
 	}
-	return newPopulation
+	return g.makeOffspring(firstIndividuals, secondIndividuals)
 }
 
-func (g GeneticAlgorithm) RunGeneticAlgorithmNSGA2(numberOfSegments int) Population {
-	nsga3 := NSGA3{}
-	parentPopulation := g.GenerateRandomFeasiblePopulation()
-
-	for t := 0; t < g.NumberOfGenerations; t++ {
-		referencePoints := nsga3.GetReferencePoints(len(parentPopulation[0].ObjectiveValues), numberOfSegments)
-		nextPopulation := nsga3.GenerateNextPopulation(t, g, parentPopulation, referencePoints)
-		parentPopulation = nextPopulation
-		for _, individual := range parentPopulation {
-			individual.ReferencePoint = ReferencePoint{}
-			individual.PerpendicularDistance = 0
-			individual.IndividualsDominatedByThis = []*Individual{}
-			individual.NumberOfIndividualsDominateThis = 0
-			individual.Rank = 0
+// makeOffspring reproduces the i-th first and second individuals into the i-th offspring.
+func (g GeneticAlgorithm) makeOffspring(firstIndividuals []Individual, secondIndividuals []Individual) Population {
+	offspring := g.generateConcurrently(len(firstIndividuals), func(g GeneticAlgorithm, i int) *Individual {
+		newIndividual := g.reproduce(firstIndividuals[i], secondIndividuals[i])
+
+		if g.random().Float64() < g.mutationProbability() {
+			g.mutate(&newIndividual)
+		}
+		if g.Repair != nil && !newIndividual.IsFeasible {
+			g.Repair.Repair(&newIndividual)
 		}
+		return &newIndividual
+	})
+	g.mutationWeights.recordOffspring(offspring)
+	return offspring
+}
 
-	}
-	return parentPopulation
+// RunGeneticAlgorithmNSGA2 runs NSGA-III with numberOfSegments segments per objective axis.
+// It returns the error of Run when the configuration is rejected, for instance when the
+// population is smaller than the number of reference points.
+func (g GeneticAlgorithm) RunGeneticAlgorithmNSGA2(numberOfSegments int) (Population, error) {
+	return g.Run(&NSGA3{NumberOfSegments: numberOfSegments})
 }
 
 func printRemainingResources(parentPopulation Population) {
-	for _, n := range parentPopulation[0].AllNodes {
-		fmt.Print("Node[", n.ID+"]:  ")
-		for _, t := range n.Tasks {
-			fmt.Print(t.TaskID + ", ")
+	individual := parentPopulation[0]
+	problem := individual.Problem
+	for n, node := range problem.Nodes {
+		fmt.Print("Node[", node.ID+"]:  ")
+		for _, t := range individual.TasksOfNode(n) {
+			fmt.Print(problem.Tasks[t].TaskID + ", ")
 		}
-		fmt.Println("remainng ", n.RemainingResources, "cpu", (n.RemainingResources.CpuCores / n.AvailableResources.CpuCores),
-			"mem", (n.RemainingResources.Memory / n.AvailableResources.Memory))
+		fmt.Println("remainng ", individual.RemainingResourcesOfNode(n), "cpu", (individual.RemainingResource(n, ResourceCpuCores) / node.AvailableResources.CpuCores),
+			"mem", (individual.RemainingResource(n, ResourceMemory) / node.AvailableResources.Memory))
 	}
 
 	fmt.Println("+++++++++++++++++++++++")
-	for _, task := range parentPopulation[0].AllTasks {
-		if task.NodeID == "" {
+	for t, task := range problem.Tasks {
+		if individual.Genome[t] == Unassigned {
 			fmt.Println(task.TaskID, " ", task.RequiredResources)
 		}
 	}
@@ -321,17 +297,17 @@ func printResourcesUtilization(parentPopulation Population) {
 }
 
 func printResourcePercentageForEachNode(p Population) {
-	var bestInd Individual
+	var bestInd *Individual
 	bestVal := math.MaxFloat64
 	for _, ind := range p {
 		if ind.ObjectiveValues[2] < bestVal {
 			bestVal = ind.ObjectiveValues[2]
-			bestInd = *ind
+			bestInd = ind
 		}
 	}
 
-	for _, node := range bestInd.AllNodes {
-		fmt.Println(node.RemainingResources.CpuCores/node.AvailableResources.CpuCores,
-			node.RemainingResources.Memory/node.AvailableResources.Memory)
+	for n, node := range bestInd.Problem.Nodes {
+		fmt.Println(bestInd.RemainingResource(n, ResourceCpuCores)/node.AvailableResources.CpuCores,
+			bestInd.RemainingResource(n, ResourceMemory)/node.AvailableResources.Memory)
 	}
 }
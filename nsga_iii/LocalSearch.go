@@ -0,0 +1,158 @@
+package nsga_iii
+
+import (
+	"math"
+)
+
+const (
+	defaultLocalSearchPeriod       = 10
+	defaultLocalSearchSampleSize   = 4
+	defaultLocalSearchMaximumSteps = 50
+)
+
+// LocalSearchOptions configure the memetic phase of a run, which improves a sample of the
+// first front with a bounded hill climb after every Period generations. The climbed
+// individuals replace the originals in the population of NSGA2, NSGA3, ThetaDEA and SPEA2,
+// which select among the whole population. MOEAD keeps them only when they solve the
+// subproblem of the original at least as well.
+type LocalSearchOptions struct {
+	Enabled bool
+	// Period is the number of generations between two local search phases, 10 if not positive.
+	Period int
+	// SampleSize is the number of first front individuals improved in every phase, 4 if not positive.
+	SampleSize int
+	// MaximumSteps bounds the number of neighbors evaluated for every individual, 50 if not positive.
+	MaximumSteps int
+}
+
+func (options LocalSearchOptions) period() int {
+	if options.Period <= 0 {
+		return defaultLocalSearchPeriod
+	}
+	return options.Period
+}
+
+func (options LocalSearchOptions) sampleSize() int {
+	if options.SampleSize <= 0 {
+		return defaultLocalSearchSampleSize
+	}
+	return options.SampleSize
+}
+
+func (options LocalSearchOptions) maximumSteps() int {
+	if options.MaximumSteps <= 0 {
+		return defaultLocalSearchMaximumSteps
+	}
+	return options.MaximumSteps
+}
+
+// localSearchAcceptor is implemented by the optimizers whose individuals are tied to their
+// position in the population, which decide whether the climbed individual replaces the
+// individual at index.
+type localSearchAcceptor interface {
+	acceptsLocalSearch(population Population, index int, climbedIndividual *Individual) bool
+}
+
+// searchLocally replaces a random sample of the individuals of the population no other
+// individual constraint-dominates by the result of their hill climb, when the generation
+// ends a period of the local search and the optimizer accepts the result.
+func (g GeneticAlgorithm) searchLocally(optimizer Optimizer, generation int, population Population) Population {
+	if !g.LocalSearch.Enabled || (generation+1)%g.LocalSearch.period() != 0 {
+		return population
+	}
+
+	var firstFront []int
+	for i, individual := range population {
+		if !isConstraintDominatedByAnyOf(individual, population) {
+			firstFront = append(firstFront, i)
+		}
+	}
+	random := g.random()
+	random.Shuffle(len(firstFront), func(i, j int) {
+		firstFront[i], firstFront[j] = firstFront[j], firstFront[i]
+	})
+	if len(firstFront) > g.LocalSearch.sampleSize() {
+		firstFront = firstFront[:g.LocalSearch.sampleSize()]
+	}
+
+	scalarization := newRangeScalarization(population)
+	improvedIndividuals := g.generateConcurrently(len(firstFront), func(g GeneticAlgorithm, i int) *Individual {
+		return g.climbHill(population[firstFront[i]], scalarization)
+	})
+	acceptor, hasAcceptor := optimizer.(localSearchAcceptor)
+	nextPopulation := append(Population{}, population...)
+	for i, index := range firstFront {
+		if hasAcceptor && !acceptor.acceptsLocalSearch(nextPopulation, index, improvedIndividuals[i]) {
+			continue
+		}
+		nextPopulation[index] = improvedIndividuals[i]
+	}
+	return nextPopulation
+}
+
+// climbHill returns a copy of the individual improved by up to MaximumSteps random
+// neighbors, moving a task to another allowed node or swapping the nodes of two tasks.
+// A neighbor is accepted when it violates the constraints less, or as much while it
+// dominates the individual or improves its scalarized objective values.
+func (g GeneticAlgorithm) climbHill(individual *Individual, scalarization rangeScalarization) *Individual {
+	random := g.random()
+	problem := individual.Problem
+	current := problem.NewIndividual("", append([]int32{}, individual.Genome...))
+	for step := 0; step < g.LocalSearch.maximumSteps(); step++ {
+		genome := append([]int32{}, current.Genome...)
+		task := random.Intn(len(genome))
+		if random.Intn(2) == 0 {
+			allowedNodes := problem.allowedNodeIndexes[task]
+			genome[task] = allowedNodes[random.Intn(len(allowedNodes))]
+		} else {
+			anotherTask := random.Intn(len(genome))
+			genome[task], genome[anotherTask] = genome[anotherTask], genome[task]
+		}
+		if genome[task] == current.Genome[task] {
+			continue
+		}
+
+		neighbor := problem.NewIndividual("", genome)
+		comparison := neighbor.compareConstraintViolation(*current)
+		if comparison < 0 || (comparison == 0 && (neighbor.dominates(*current) ||
+			scalarization.scalarize(neighbor.ObjectiveValues) < scalarization.scalarize(current.ObjectiveValues))) {
+			current = neighbor
+		}
+	}
+	current.ID = g.nextIndividualID()
+	return current
+}
+
+// rangeScalarization sums the objective values scaled to their range in a population.
+type rangeScalarization struct {
+	minimumObjectiveValues []float64
+	objectiveRanges        []float64
+}
+
+func newRangeScalarization(population Population) rangeScalarization {
+	numberOfObjectiveFunctions := len(population[0].ObjectiveValues)
+	scalarization := rangeScalarization{
+		minimumObjectiveValues: make([]float64, numberOfObjectiveFunctions),
+		objectiveRanges:        make([]float64, numberOfObjectiveFunctions),
+	}
+	worstObjectivePoint := computeWorstObjectivePoint(population)
+	for k := range scalarization.minimumObjectiveValues {
+		scalarization.minimumObjectiveValues[k] = computeIdealObjectiveValue(population, k)
+		scalarization.objectiveRanges[k] = worstObjectivePoint[k] - scalarization.minimumObjectiveValues[k]
+		if scalarization.objectiveRanges[k] <= 0 {
+			scalarization.objectiveRanges[k] = 1
+		}
+	}
+	return scalarization
+}
+
+func (scalarization rangeScalarization) scalarize(objectiveValues []float64) float64 {
+	scalarValue := 0.0
+	for k, objectiveValue := range objectiveValues {
+		scalarValue += (objectiveValue - scalarization.minimumObjectiveValues[k]) / scalarization.objectiveRanges[k]
+	}
+	if math.IsNaN(scalarValue) {
+		return math.Inf(1)
+	}
+	return scalarValue
+}
@@ -0,0 +1,173 @@
+package nsga_iii
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	defaultMOEADNeighborhoodSize    = 20
+	defaultMOEADMaximumReplacements = 2
+)
+
+// MOEAD decomposes the objectives into one scalar subproblem per individual, the Tchebycheff
+// distance to the ideal point weighted by a weight vector, and solves every subproblem with
+// the offspring of the individuals of its neighboring subproblems. The offspring of a
+// generation are created together, so an offspring may solve several subproblems.
+type MOEAD struct {
+	// WeightVectors holds the weight vector of every individual. When empty, the reference
+	// points of NumberOfSegments are used, which must then be as many as the individuals.
+	WeightVectors    [][]float64
+	NumberOfSegments int
+	// NeighborhoodSize is the number of closest weight vectors whose subproblems exchange
+	// parents and offspring, 20 if not positive.
+	NeighborhoodSize int
+	// MaximumReplacements bounds the number of subproblems solved by an offspring, 2 if not positive.
+	MaximumReplacements int
+
+	weightVectors [][]float64
+	neighborhoods [][]int
+	idealPoint    []float64
+}
+
+func (moead *MOEAD) Initialize(g GeneticAlgorithm, population Population) error {
+	numberOfObjectiveFunctions := len(population[0].ObjectiveValues)
+	moead.weightVectors = moead.WeightVectors
+	if len(moead.weightVectors) == 0 {
+		if moead.NumberOfSegments < 1 {
+			return fmt.Errorf("MOEA/D needs weight vectors or at least one segment per objective axis")
+		}
+		moead.weightVectors = nil
+		for _, referencePoint := range (NSGA3{}).GetReferencePoints(numberOfObjectiveFunctions, moead.NumberOfSegments) {
+			moead.weightVectors = append(moead.weightVectors, referencePoint.Coordinates)
+		}
+	}
+	if len(moead.weightVectors) != len(population) {
+		return fmt.Errorf("MOEA/D needs one weight vector per individual, got %d weight vectors for %d individuals", len(moead.weightVectors), len(population))
+	}
+	for _, weightVector := range moead.weightVectors {
+		if len(weightVector) != numberOfObjectiveFunctions {
+			return fmt.Errorf("MOEA/D weight vectors must have %d components, got %d", numberOfObjectiveFunctions, len(weightVector))
+		}
+	}
+
+	neighborhoodSize := moead.NeighborhoodSize
+	if neighborhoodSize <= 0 {
+		neighborhoodSize = defaultMOEADNeighborhoodSize
+	}
+	if neighborhoodSize > len(population) {
+		neighborhoodSize = len(population)
+	}
+	moead.neighborhoods = make([][]int, len(moead.weightVectors))
+	for i, weightVector := range moead.weightVectors {
+		distances := make([]float64, len(moead.weightVectors))
+		neighborhood := make([]int, len(moead.weightVectors))
+		for j, anotherWeightVector := range moead.weightVectors {
+			distances[j] = euclideanDistance(weightVector, anotherWeightVector)
+			neighborhood[j] = j
+		}
+		sort.SliceStable(neighborhood, func(a, b int) bool {
+			return distances[neighborhood[a]] < distances[neighborhood[b]]
+		})
+		moead.neighborhoods[i] = neighborhood[:neighborhoodSize]
+	}
+
+	moead.idealPoint = make([]float64, numberOfObjectiveFunctions)
+	for k := range moead.idealPoint {
+		moead.idealPoint[k] = math.MaxFloat64
+	}
+	moead.updateIdealPoint(population)
+	return nil
+}
+
+func (moead *MOEAD) NextPopulation(g GeneticAlgorithm, generation int, parentPopulation Population) Population {
+	firstIndividuals := make([]Individual, len(parentPopulation))
+	secondIndividuals := make([]Individual, len(parentPopulation))
+	for i := range parentPopulation {
+		neighborhood := moead.neighborhoods[i]
+		firstIndividuals[i] = *parentPopulation[neighborhood[g.random().Intn(len(neighborhood))]]
+		secondIndividuals[i] = *parentPopulation[neighborhood[g.random().Intn(len(neighborhood))]]
+	}
+	newPopulation := g.makeOffspring(firstIndividuals, secondIndividuals)
+	moead.updateIdealPoint(newPopulation)
+	nadirPoint := moead.computeNadirPoint(append(append(Population{}, parentPopulation...), newPopulation...))
+
+	maximumReplacements := moead.MaximumReplacements
+	if maximumReplacements <= 0 {
+		maximumReplacements = defaultMOEADMaximumReplacements
+	}
+	nextPopulation := append(Population{}, parentPopulation...)
+	for i, newIndividual := range newPopulation {
+		numberOfReplacements := 0
+		for _, j := range moead.neighborhoods[i] {
+			if numberOfReplacements == maximumReplacements {
+				break
+			}
+			if moead.solvesSubproblemBetter(newIndividual, nextPopulation[j], j, nadirPoint) {
+				nextPopulation[j] = newIndividual
+				numberOfReplacements++
+			}
+		}
+	}
+	return nextPopulation
+}
+
+func (moead *MOEAD) updateIdealPoint(population Population) {
+	for _, individual := range population {
+		for k, objectiveValue := range individual.ObjectiveValues {
+			moead.idealPoint[k] = math.Min(moead.idealPoint[k], objectiveValue)
+		}
+	}
+}
+
+func (moead *MOEAD) computeNadirPoint(population Population) []float64 {
+	nadirPoint := make([]float64, len(moead.idealPoint))
+	for k := range nadirPoint {
+		nadirPoint[k] = -math.MaxFloat64
+		for _, individual := range population {
+			nadirPoint[k] = math.Max(nadirPoint[k], individual.ObjectiveValues[k])
+		}
+	}
+	return nadirPoint
+}
+
+// acceptsLocalSearch updates the ideal point with the climbed individual, like every offspring,
+// and accepts it when it solves the subproblem of index at least as well as its individual.
+func (moead *MOEAD) acceptsLocalSearch(population Population, index int, climbedIndividual *Individual) bool {
+	moead.updateIdealPoint(Population{climbedIndividual})
+	nadirPoint := moead.computeNadirPoint(append(append(Population{}, population...), climbedIndividual))
+	return moead.solvesSubproblemBetter(climbedIndividual, population[index], index, nadirPoint)
+}
+
+// solvesSubproblemBetter prefers the individual violating the constraints less, and then the
+// one with the smaller Tchebycheff distance.
+func (moead *MOEAD) solvesSubproblemBetter(individual *Individual, anotherIndividual *Individual, subproblem int, nadirPoint []float64) bool {
+	if comparison := individual.compareConstraintViolation(*anotherIndividual); comparison != 0 {
+		return comparison < 0
+	}
+	return moead.tchebycheff(individual, subproblem, nadirPoint) <= moead.tchebycheff(anotherIndividual, subproblem, nadirPoint)
+}
+
+// tchebycheff returns the largest weighted distance of the individual to the ideal point
+// over the objectives, each scaled by its range in the population.
+func (moead *MOEAD) tchebycheff(individual *Individual, subproblem int, nadirPoint []float64) float64 {
+	tchebycheffValue := 0.0
+	for k, objectiveValue := range individual.ObjectiveValues {
+		objectiveRange := nadirPoint[k] - moead.idealPoint[k]
+		if objectiveRange <= 0 {
+			objectiveRange = 1
+		}
+		weight := math.Max(moead.weightVectors[subproblem][k], math.Pow(10, -6))
+		tchebycheffValue = math.Max(tchebycheffValue, weight*math.Abs(objectiveValue-moead.idealPoint[k])/objectiveRange)
+	}
+	return tchebycheffValue
+}
+
+func euclideanDistance(coordinates []float64, anotherCoordinates []float64) float64 {
+	distance := 0.0
+	for i := range coordinates {
+		distance += math.Pow(coordinates[i]-anotherCoordinates[i], 2.0)
+	}
+	return math.Sqrt(distance)
+}
@@ -0,0 +1,36 @@
+package nsga_iii
+
+import (
+	"testing"
+)
+
+func TestMOEADAcceptsLocalSearch(t *testing.T) {
+	newIndividual := func(objectiveValues ...float64) *Individual {
+		return &Individual{ObjectiveValues: objectiveValues, IsFeasible: true}
+	}
+	tests := []struct {
+		name              string
+		index             int
+		climbedIndividual *Individual
+		isAccepted        bool
+	}{
+		{name: "better on its subproblem", index: 0, climbedIndividual: newIndividual(0.5, 9), isAccepted: true},
+		{name: "worse on its subproblem", index: 1, climbedIndividual: newIndividual(0.5, 9), isAccepted: false},
+		{name: "infeasible", index: 0, climbedIndividual: &Individual{ObjectiveValues: []float64{0.5, 5}, ConstrainedViolationValue: 1}, isAccepted: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			population := Population{newIndividual(1, 5), newIndividual(5, 1)}
+			moead := &MOEAD{WeightVectors: [][]float64{{1, 0}, {0, 1}}}
+			if err := moead.Initialize(GeneticAlgorithm{}, population); err != nil {
+				t.Fatal(err)
+			}
+			if isAccepted := moead.acceptsLocalSearch(population, test.index, test.climbedIndividual); isAccepted != test.isAccepted {
+				t.Fatalf("acceptsLocalSearch is %v, want %v", isAccepted, test.isAccepted)
+			}
+			if moead.idealPoint[0] != 0.5 {
+				t.Fatalf("the ideal point %v misses the climbed individual", moead.idealPoint)
+			}
+		})
+	}
+}
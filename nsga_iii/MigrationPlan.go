@@ -0,0 +1,139 @@
+package nsga_iii
+
+import (
+	"fmt"
+)
+
+type MigrationStepKind string
+
+const (
+	MigrationStepStart MigrationStepKind = "start"
+	MigrationStepMove  MigrationStepKind = "move"
+	MigrationStepStop  MigrationStepKind = "stop"
+)
+
+// MigrationStep starts a task on ToNodeID, moves it from FromNodeID to ToNodeID by
+// starting the new replica before stopping the old one, or stops it on FromNodeID.
+type MigrationStep struct {
+	Kind       MigrationStepKind
+	TaskID     string
+	FromNodeID string
+	ToNodeID   string
+}
+
+type MigrationPlan []MigrationStep
+
+type pendingMigration struct {
+	taskIndex     int
+	fromNodeIndex int32
+	toNodeIndex   int32
+	isParked      bool
+}
+
+// MigrationPlan orders the steps turning the original assignment of the individual
+// into its assignment, so that no node exceeds its available resources after any step.
+// Moves that block each other in a cycle go through a temporary parking node, or are split
+// into a stop and a later start when no node can park the task.
+func (individual *Individual) MigrationPlan() (MigrationPlan, error) {
+	problem := individual.Problem
+	for n, node := range problem.Nodes {
+		for r, remaining := range individual.RemainingResourcesOfNode(n) {
+			if remaining < 0 {
+				return nil, fmt.Errorf("node %s exceeds its available %s in the target assignment", node.ID, problem.ResourceNames[r])
+			}
+		}
+	}
+
+	var migrationPlan MigrationPlan
+	placement := newPlacement(problem)
+	var stoppedTasks []int
+	var pendingMigrations []*pendingMigration
+	for t, task := range problem.Tasks {
+		fromNodeIndex := problem.OriginalAssignment[t]
+		if fromNodeIndex != Unassigned {
+			placement.place(t, fromNodeIndex)
+		}
+		toNodeIndex := individual.Genome[t]
+		if fromNodeIndex != Unassigned && toNodeIndex == fromNodeIndex {
+			continue
+		}
+		if fromNodeIndex != Unassigned && toNodeIndex == Unassigned {
+			migrationPlan = append(migrationPlan, MigrationStep{Kind: MigrationStepStop, TaskID: task.TaskID, FromNodeID: problem.Nodes[fromNodeIndex].ID})
+			stoppedTasks = append(stoppedTasks, t)
+			continue
+		}
+		if toNodeIndex < Unassigned || int(toNodeIndex) >= len(problem.Nodes) {
+			return nil, fmt.Errorf("task %s is assigned to unknown node index %d", task.TaskID, toNodeIndex)
+		}
+		if toNodeIndex != Unassigned {
+			pendingMigrations = append(pendingMigrations, &pendingMigration{taskIndex: t, fromNodeIndex: fromNodeIndex, toNodeIndex: toNodeIndex})
+		}
+	}
+	for _, t := range stoppedTasks {
+		placement.remove(t)
+	}
+
+	for len(pendingMigrations) > 0 {
+		var blockedMigrations []*pendingMigration
+		for _, migration := range pendingMigrations {
+			if problem.fits(migration.taskIndex, placement.remainingResourcesOfNode(migration.toNodeIndex)) {
+				migrationPlan = append(migrationPlan, migration.execute(placement, migration.toNodeIndex))
+			} else {
+				blockedMigrations = append(blockedMigrations, migration)
+			}
+		}
+
+		if len(blockedMigrations) == len(pendingMigrations) {
+			step, err := breakMigrationCycle(placement, blockedMigrations)
+			if err != nil {
+				return nil, err
+			}
+			migrationPlan = append(migrationPlan, step)
+		}
+		pendingMigrations = blockedMigrations
+	}
+	return migrationPlan, nil
+}
+
+func (migration *pendingMigration) execute(placement *placement, toNodeIndex int32) MigrationStep {
+	nodes := placement.problem.Nodes
+	taskID := placement.problem.Tasks[migration.taskIndex].TaskID
+	placement.remove(migration.taskIndex)
+	placement.place(migration.taskIndex, toNodeIndex)
+	if migration.fromNodeIndex == Unassigned {
+		return MigrationStep{Kind: MigrationStepStart, TaskID: taskID, ToNodeID: nodes[toNodeIndex].ID}
+	}
+	return MigrationStep{Kind: MigrationStepMove, TaskID: taskID, FromNodeID: nodes[migration.fromNodeIndex].ID, ToNodeID: nodes[toNodeIndex].ID}
+}
+
+// breakMigrationCycle frees resources for the blocked migrations by moving one of their tasks
+// to a parking node the task can be placed on, resources, node affinity and task affinity
+// included, or by stopping it until its target node has room when no node can park it.
+func breakMigrationCycle(placement *placement, blockedMigrations []*pendingMigration) (MigrationStep, error) {
+	for _, migration := range blockedMigrations {
+		if migration.fromNodeIndex == Unassigned || migration.isParked {
+			continue
+		}
+		for n := range placement.problem.Nodes {
+			parkingNodeIndex := int32(n)
+			if parkingNodeIndex == migration.fromNodeIndex || parkingNodeIndex == migration.toNodeIndex ||
+				!placement.canPlace(migration.taskIndex, parkingNodeIndex) {
+				continue
+			}
+			step := migration.execute(placement, parkingNodeIndex)
+			migration.fromNodeIndex = parkingNodeIndex
+			migration.isParked = true
+			return step, nil
+		}
+	}
+
+	for _, migration := range blockedMigrations {
+		if migration.fromNodeIndex != Unassigned {
+			placement.remove(migration.taskIndex)
+			step := MigrationStep{Kind: MigrationStepStop, TaskID: placement.problem.Tasks[migration.taskIndex].TaskID, FromNodeID: placement.problem.Nodes[migration.fromNodeIndex].ID}
+			migration.fromNodeIndex = Unassigned
+			return step, nil
+		}
+	}
+	return MigrationStep{}, fmt.Errorf("tasks cannot be started on their target nodes without exceeding their available resources")
+}
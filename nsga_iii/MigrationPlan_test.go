@@ -0,0 +1,165 @@
+package nsga_iii
+
+import (
+	"testing"
+)
+
+func TestIndividualMigrationPlan(t *testing.T) {
+	onlyZones := func(zones ...string) NodeAffinity {
+		return NodeAffinity{Required: []NodeSelectorTerm{{MatchExpressions: []NodeSelectorRequirement{{Key: "zone", Operator: NodeSelectorOpIn, Values: zones}}}}}
+	}
+	tests := []struct {
+		name                  string
+		nodes                 []Node
+		tasks                 []Task
+		originalAssignment    map[string]string
+		assignment            map[string]string
+		expectedMigrationPlan MigrationPlan
+		isErrorExpected       bool
+	}{
+		{
+			name: "swap cycle resolved by parking",
+			nodes: []Node{
+				{ID: "a", AvailableResources: Resources{CpuCores: 2, Memory: 2}},
+				{ID: "b", AvailableResources: Resources{CpuCores: 2, Memory: 2}},
+				{ID: "c", AvailableResources: Resources{CpuCores: 2, Memory: 2}},
+			},
+			tasks: []Task{
+				{TaskID: "x", RequiredResources: Resources{CpuCores: 2, Memory: 2}},
+				{TaskID: "y", RequiredResources: Resources{CpuCores: 2, Memory: 2}},
+			},
+			originalAssignment: map[string]string{"x": "a", "y": "b"},
+			assignment:         map[string]string{"x": "b", "y": "a"},
+			expectedMigrationPlan: MigrationPlan{
+				{Kind: MigrationStepMove, TaskID: "x", FromNodeID: "a", ToNodeID: "c"},
+				{Kind: MigrationStepMove, TaskID: "y", FromNodeID: "b", ToNodeID: "a"},
+				{Kind: MigrationStepMove, TaskID: "x", FromNodeID: "c", ToNodeID: "b"},
+			},
+		},
+		{
+			name: "swap cycle parked on a node allowed by the node affinity",
+			nodes: []Node{
+				{ID: "a", AvailableResources: Resources{CpuCores: 2, Memory: 2}, Labels: map[string]string{"zone": "1"}},
+				{ID: "b", AvailableResources: Resources{CpuCores: 2, Memory: 2}, Labels: map[string]string{"zone": "1"}},
+				{ID: "c", AvailableResources: Resources{CpuCores: 2, Memory: 2}, Labels: map[string]string{"zone": "2"}},
+				{ID: "d", AvailableResources: Resources{CpuCores: 2, Memory: 2}, Labels: map[string]string{"zone": "1"}},
+			},
+			tasks: []Task{
+				{TaskID: "x", RequiredResources: Resources{CpuCores: 2, Memory: 2}, NodeAffinity: onlyZones("1")},
+				{TaskID: "y", RequiredResources: Resources{CpuCores: 2, Memory: 2}, NodeAffinity: onlyZones("1")},
+			},
+			originalAssignment: map[string]string{"x": "a", "y": "b"},
+			assignment:         map[string]string{"x": "b", "y": "a"},
+			expectedMigrationPlan: MigrationPlan{
+				{Kind: MigrationStepMove, TaskID: "x", FromNodeID: "a", ToNodeID: "d"},
+				{Kind: MigrationStepMove, TaskID: "y", FromNodeID: "b", ToNodeID: "a"},
+				{Kind: MigrationStepMove, TaskID: "x", FromNodeID: "d", ToNodeID: "b"},
+			},
+		},
+		{
+			name: "swap cycle without parking node resolved by stop and start",
+			nodes: []Node{
+				{ID: "a", AvailableResources: Resources{CpuCores: 2, Memory: 2}},
+				{ID: "b", AvailableResources: Resources{CpuCores: 2, Memory: 2}},
+				{ID: "c", AvailableResources: Resources{CpuCores: 1, Memory: 1}},
+			},
+			tasks: []Task{
+				{TaskID: "x", RequiredResources: Resources{CpuCores: 2, Memory: 2}},
+				{TaskID: "y", RequiredResources: Resources{CpuCores: 2, Memory: 2}},
+			},
+			originalAssignment: map[string]string{"x": "a", "y": "b"},
+			assignment:         map[string]string{"x": "b", "y": "a"},
+			expectedMigrationPlan: MigrationPlan{
+				{Kind: MigrationStepStop, TaskID: "x", FromNodeID: "a"},
+				{Kind: MigrationStepMove, TaskID: "y", FromNodeID: "b", ToNodeID: "a"},
+				{Kind: MigrationStepStart, TaskID: "x", ToNodeID: "b"},
+			},
+		},
+		{
+			name: "stopped task frees its node before the moves",
+			nodes: []Node{
+				{ID: "a", AvailableResources: Resources{CpuCores: 2, Memory: 2}},
+				{ID: "b", AvailableResources: Resources{CpuCores: 2, Memory: 2}},
+			},
+			tasks: []Task{
+				{TaskID: "x", RequiredResources: Resources{CpuCores: 2, Memory: 2}},
+				{TaskID: "y", RequiredResources: Resources{CpuCores: 2, Memory: 2}},
+			},
+			originalAssignment: map[string]string{"x": "a", "y": "b"},
+			assignment:         map[string]string{"y": "a"},
+			expectedMigrationPlan: MigrationPlan{
+				{Kind: MigrationStepStop, TaskID: "x", FromNodeID: "a"},
+				{Kind: MigrationStepMove, TaskID: "y", FromNodeID: "b", ToNodeID: "a"},
+			},
+		},
+		{
+			name: "infeasible target assignment",
+			nodes: []Node{
+				{ID: "a", AvailableResources: Resources{CpuCores: 2, Memory: 2}},
+				{ID: "b", AvailableResources: Resources{CpuCores: 2, Memory: 2}},
+			},
+			tasks: []Task{
+				{TaskID: "x", RequiredResources: Resources{CpuCores: 2, Memory: 2}},
+				{TaskID: "y", RequiredResources: Resources{CpuCores: 2, Memory: 2}},
+			},
+			originalAssignment: map[string]string{"x": "a", "y": "b"},
+			assignment:         map[string]string{"x": "a", "y": "a"},
+			isErrorExpected:    true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			problem := NewProblem(test.nodes, test.tasks, test.originalAssignment)
+			individual := problem.NewIndividual("", problem.GenomeOf(test.assignment))
+			migrationPlan, err := individual.MigrationPlan()
+			if test.isErrorExpected {
+				if err == nil {
+					t.Fatalf("got migration plan %v, want an error", migrationPlan)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(migrationPlan) != len(test.expectedMigrationPlan) {
+				t.Fatalf("got migration plan %v, want %v", migrationPlan, test.expectedMigrationPlan)
+			}
+			for i := range migrationPlan {
+				if migrationPlan[i] != test.expectedMigrationPlan[i] {
+					t.Fatalf("got migration plan %v, want %v", migrationPlan, test.expectedMigrationPlan)
+				}
+			}
+
+			// replay the steps from the original assignment
+			p := placementOf(problem, problem.OriginalAssignment)
+			for i, step := range migrationPlan {
+				taskIndex, _ := problem.TaskIndex(step.TaskID)
+				if step.Kind != MigrationStepStart {
+					if nodeIndex, _ := problem.NodeIndex(step.FromNodeID); p.genome[taskIndex] != nodeIndex {
+						t.Fatalf("step %d %v: task %s is not on %s", i, step, step.TaskID, step.FromNodeID)
+					}
+					p.remove(int(taskIndex))
+				}
+				if step.Kind != MigrationStepStop {
+					nodeIndex, _ := problem.NodeIndex(step.ToNodeID)
+					if !problem.allowsNode(int(taskIndex), nodeIndex) {
+						t.Fatalf("step %d %v: node affinity of task %s forbids %s", i, step, step.TaskID, step.ToNodeID)
+					}
+					p.place(int(taskIndex), nodeIndex)
+				}
+				for n := range problem.Nodes {
+					for r, remainingResource := range p.remainingResourcesOfNode(int32(n)) {
+						if remainingResource < 0 {
+							t.Fatalf("step %d %v exceeds the %s of node %s", i, step, problem.ResourceNames[r], problem.Nodes[n].ID)
+						}
+					}
+				}
+			}
+			for taskIndex, nodeIndex := range individual.Genome {
+				if p.genome[taskIndex] != nodeIndex {
+					t.Fatalf("task %s ends on node index %d, want %d", problem.Tasks[taskIndex].TaskID, p.genome[taskIndex], nodeIndex)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,319 @@
+package nsga_iii
+
+import (
+	"math/rand"
+)
+
+const (
+	defaultMutationProbability = 0.5
+	// mutationAdaptationRate is the weight of the last generation in the quality of a
+	// mutation operator, and minimumMutationShare the share of the weights spread evenly
+	// over the operators so that none of them stops being drawn.
+	mutationAdaptationRate = 0.3
+	minimumMutationShare   = 0.1
+)
+
+// Mutation modifies the genome of an individual in place. The other values of the
+// individual are up to date when Mutate is called and are recomputed afterwards.
+type Mutation interface {
+	Mutate(individual *Individual, random *rand.Rand)
+}
+
+// WeightedMutation is a mutation operator drawn with a probability proportional to its Weight.
+type WeightedMutation struct {
+	Mutation Mutation
+	Weight   float64
+}
+
+// DefaultMutations returns the mutation operators of the genetic algorithm with their
+// default weights.
+func DefaultMutations() []WeightedMutation {
+	return []WeightedMutation{
+		{Mutation: ChangeNodeMutation{}, Weight: 0.25},
+		{Mutation: SwapMutation{}, Weight: 0.25},
+		{Mutation: UnassignMutation{}, Weight: 0.01},
+		{Mutation: AssignUnassignedMutation{}, Weight: 0.49},
+	}
+}
+
+// ChangeNodeMutation moves a random task to a random node allowed by its node affinity.
+type ChangeNodeMutation struct{}
+
+func (mutation ChangeNodeMutation) Mutate(individual *Individual, random *rand.Rand) {
+	task := random.Intn(len(individual.Genome))
+	allowedNodes := individual.Problem.allowedNodeIndexes[task]
+	individual.Genome[task] = allowedNodes[random.Intn(len(allowedNodes))]
+}
+
+// SwapMutation swaps the nodes of two random tasks.
+type SwapMutation struct{}
+
+func (mutation SwapMutation) Mutate(individual *Individual, random *rand.Rand) {
+	task1 := random.Intn(len(individual.Genome))
+	task2 := random.Intn(len(individual.Genome))
+
+	individual.Genome[task1], individual.Genome[task2] = individual.Genome[task2], individual.Genome[task1]
+}
+
+// UnassignMutation unassigns a random assigned task of an infeasible individual.
+type UnassignMutation struct{}
+
+func (mutation UnassignMutation) Mutate(individual *Individual, random *rand.Rand) {
+	if individual.IsFeasible {
+		return
+	}
+	var assignedTasks []int
+	for t, nodeIndex := range individual.Genome {
+		if nodeIndex != Unassigned {
+			assignedTasks = append(assignedTasks, t)
+		}
+	}
+	if len(assignedTasks) == 0 {
+		return
+	}
+	individual.Genome[assignedTasks[random.Intn(len(assignedTasks))]] = Unassigned
+}
+
+// AssignUnassignedMutation assigns a random unassigned task to a random node allowed by
+// its node affinity.
+type AssignUnassignedMutation struct{}
+
+func (mutation AssignUnassignedMutation) Mutate(individual *Individual, random *rand.Rand) {
+	if individual.NumberOfUnassignedTasks == 0 {
+		return
+	}
+	var unassignedTasks []int
+	for t, nodeIndex := range individual.Genome {
+		if nodeIndex == Unassigned {
+			unassignedTasks = append(unassignedTasks, t)
+		}
+	}
+	unassignedTask := unassignedTasks[random.Intn(len(unassignedTasks))]
+	allowedNodes := individual.Problem.allowedNodeIndexes[unassignedTask]
+	individual.Genome[unassignedTask] = allowedNodes[random.Intn(len(allowedNodes))]
+}
+
+// MoveToLeastLoadedMutation moves a random task of the most loaded node to the least
+// loaded node it can be placed on, the load of a node being the largest share of a
+// resource used by its tasks.
+type MoveToLeastLoadedMutation struct{}
+
+func (mutation MoveToLeastLoadedMutation) Mutate(individual *Individual, random *rand.Rand) {
+	placement := placementOf(individual.Problem, individual.Genome)
+	mostLoadedNode := Unassigned
+	for n := range placement.tasksOfNode {
+		if len(placement.tasksOfNode[n]) != 0 && (mostLoadedNode == Unassigned || placement.utilizationOfNode(int32(n)) > placement.utilizationOfNode(mostLoadedNode)) {
+			mostLoadedNode = int32(n)
+		}
+	}
+	if mostLoadedNode == Unassigned {
+		return
+	}
+
+	tasks := placement.tasksOfNode[mostLoadedNode]
+	task := int(tasks[random.Intn(len(tasks))])
+	placement.remove(task)
+	leastLoadedNode := Unassigned
+	for n := range placement.tasksOfNode {
+		if int32(n) != mostLoadedNode && placement.canPlace(task, int32(n)) &&
+			(leastLoadedNode == Unassigned || placement.utilizationOfNode(int32(n)) < placement.utilizationOfNode(leastLoadedNode)) {
+			leastLoadedNode = int32(n)
+		}
+	}
+	if leastLoadedNode != Unassigned {
+		individual.Genome[task] = leastLoadedNode
+	}
+}
+
+// EvacuateNodeMutation moves every task of a random used node to the first of the other
+// nodes, in random order, it can be placed on, so that the node can be powered off. Tasks
+// that cannot be placed elsewhere stay on the node.
+type EvacuateNodeMutation struct{}
+
+func (mutation EvacuateNodeMutation) Mutate(individual *Individual, random *rand.Rand) {
+	placement := placementOf(individual.Problem, individual.Genome)
+	var usedNodes []int32
+	for n, tasks := range placement.tasksOfNode {
+		if len(tasks) != 0 {
+			usedNodes = append(usedNodes, int32(n))
+		}
+	}
+	if len(usedNodes) == 0 {
+		return
+	}
+
+	evacuatedNode := usedNodes[random.Intn(len(usedNodes))]
+	var otherNodes []int32
+	for _, nodeIndex := range placement.shuffledNodes(random) {
+		if nodeIndex != evacuatedNode {
+			otherNodes = append(otherNodes, nodeIndex)
+		}
+	}
+	for _, task := range append([]int32{}, placement.tasksOfNode[evacuatedNode]...) {
+		placement.remove(int(task))
+		if !placement.placeFirstFit(int(task), otherNodes) {
+			placement.place(int(task), evacuatedNode)
+		}
+	}
+	copy(individual.Genome, placement.genome)
+}
+
+// TaskTypeSwapMutation swaps the nodes of a random task and a random task of another TaskType
+// on another node.
+type TaskTypeSwapMutation struct{}
+
+func (mutation TaskTypeSwapMutation) Mutate(individual *Individual, random *rand.Rand) {
+	taskTypeIndexes := individual.Problem.taskTypeIndexes
+	task1 := random.Intn(len(individual.Genome))
+	var tasksOfOtherTaskTypes []int
+	for t := range individual.Genome {
+		if taskTypeIndexes[t] != taskTypeIndexes[task1] && individual.Genome[t] != individual.Genome[task1] {
+			tasksOfOtherTaskTypes = append(tasksOfOtherTaskTypes, t)
+		}
+	}
+	if len(tasksOfOtherTaskTypes) == 0 {
+		return
+	}
+	task2 := tasksOfOtherTaskTypes[random.Intn(len(tasksOfOtherTaskTypes))]
+
+	individual.Genome[task1], individual.Genome[task2] = individual.Genome[task2], individual.Genome[task1]
+}
+
+func (g GeneticAlgorithm) mutations() []WeightedMutation {
+	if len(g.Mutations) == 0 {
+		return DefaultMutations()
+	}
+	return g.Mutations
+}
+
+func (g GeneticAlgorithm) mutationProbability() float64 {
+	if g.MutationProbability <= 0 {
+		return defaultMutationProbability
+	}
+	return g.MutationProbability
+}
+
+// mutate applies a mutation operator drawn by weight to the individual.
+func (g GeneticAlgorithm) mutate(individual *Individual) {
+	random := g.random()
+	mutations := g.mutations()
+	weights := make([]float64, len(mutations))
+	for i, weightedMutation := range mutations {
+		weights[i] = weightedMutation.Weight
+	}
+	if g.mutationWeights != nil {
+		weights = g.mutationWeights.weights
+	}
+
+	totalWeight := 0.0
+	for _, weight := range weights {
+		totalWeight += weight
+	}
+	probability := random.Float64() * totalWeight
+	i := 0
+	cumulativeWeight := weights[0]
+	for probability > cumulativeWeight && i < len(weights)-1 {
+		i++
+		cumulativeWeight += weights[i]
+	}
+
+	mutations[i].Mutation.Mutate(individual, random)
+	individual.mutation = i + 1
+	individual.ComputeValues()
+}
+
+// mutationWeights holds the weights of the mutation operators of a run with AdaptiveMutation.
+// The quality of an operator is the moving average of the share of its offspring reaching
+// the first front, and the weights are proportional to the qualities.
+type mutationWeights struct {
+	weights                 []float64
+	qualities               []float64
+	numbersOfOffspring      []int
+	numbersOfFirstFrontHits []int
+}
+
+// withMutationWeights returns a copy of the genetic algorithm holding the adaptive weights
+// of the mutation operators of a run, unless AdaptiveMutation is off or it already holds them.
+func (g GeneticAlgorithm) withMutationWeights() GeneticAlgorithm {
+	if !g.AdaptiveMutation || g.mutationWeights != nil {
+		return g
+	}
+	mutations := g.mutations()
+	g.mutationWeights = &mutationWeights{
+		weights:                 make([]float64, len(mutations)),
+		qualities:               make([]float64, len(mutations)),
+		numbersOfOffspring:      make([]int, len(mutations)),
+		numbersOfFirstFrontHits: make([]int, len(mutations)),
+	}
+	for i, weightedMutation := range mutations {
+		g.mutationWeights.qualities[i] = weightedMutation.Weight
+	}
+	g.mutationWeights.updateWeights()
+	return g
+}
+
+// recordOffspring counts the offspring created by every mutation operator.
+func (mutationWeights *mutationWeights) recordOffspring(offspring Population) {
+	if mutationWeights == nil {
+		return
+	}
+	for _, individual := range offspring {
+		if individual.mutation != 0 {
+			mutationWeights.numbersOfOffspring[individual.mutation-1]++
+		}
+	}
+}
+
+// adapt credits the mutation operators with their offspring on the first front of the next
+// population and updates the weights with the offspring recorded since the last adaptation.
+// An offspring held by several individuals of the population, as with MOEAD, is credited once.
+func (mutationWeights *mutationWeights) adapt(nextPopulation Population) {
+	if mutationWeights == nil {
+		return
+	}
+	isCredited := make(map[*Individual]bool, len(nextPopulation))
+	for _, individual := range nextPopulation {
+		if isCredited[individual] {
+			continue
+		}
+		isCredited[individual] = true
+		if individual.mutation != 0 && !isConstraintDominatedByAnyOf(individual, nextPopulation) {
+			mutationWeights.numbersOfFirstFrontHits[individual.mutation-1]++
+		}
+		// survivors are only credited in the generation that created them
+		individual.mutation = 0
+	}
+	for i, numberOfOffspring := range mutationWeights.numbersOfOffspring {
+		if numberOfOffspring == 0 {
+			continue
+		}
+		reward := float64(mutationWeights.numbersOfFirstFrontHits[i]) / float64(numberOfOffspring)
+		mutationWeights.qualities[i] = (1-mutationAdaptationRate)*mutationWeights.qualities[i] + mutationAdaptationRate*reward
+		mutationWeights.numbersOfOffspring[i] = 0
+		mutationWeights.numbersOfFirstFrontHits[i] = 0
+	}
+	mutationWeights.updateWeights()
+}
+
+func (mutationWeights *mutationWeights) updateWeights() {
+	totalQuality := 0.0
+	for _, quality := range mutationWeights.qualities {
+		totalQuality += quality
+	}
+	numberOfMutations := float64(len(mutationWeights.qualities))
+	for i, quality := range mutationWeights.qualities {
+		mutationWeights.weights[i] = 1 / numberOfMutations
+		if totalQuality > 0 {
+			mutationWeights.weights[i] = minimumMutationShare/numberOfMutations + (1-minimumMutationShare)*quality/totalQuality
+		}
+	}
+}
+
+func isConstraintDominatedByAnyOf(individual *Individual, population Population) bool {
+	for _, anotherIndividual := range population {
+		if anotherIndividual.constraintDominate(*individual) {
+			return true
+		}
+	}
+	return false
+}
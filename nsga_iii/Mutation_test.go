@@ -0,0 +1,28 @@
+package nsga_iii
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMutationWeightsAdaptCreditsEveryOffspringOnce(t *testing.T) {
+	g := GeneticAlgorithm{AdaptiveMutation: true}.withMutationWeights()
+	mutationWeights := g.mutationWeights
+	initialQuality := mutationWeights.qualities[0]
+
+	offspring := &Individual{ObjectiveValues: []float64{1, 1}, IsFeasible: true, mutation: 1}
+	dominatedIndividual := &Individual{ObjectiveValues: []float64{2, 2}, IsFeasible: true}
+	mutationWeights.recordOffspring(Population{offspring})
+	// the offspring replaced several individuals, as with MOEAD
+	mutationWeights.adapt(Population{offspring, dominatedIndividual, offspring, offspring})
+
+	expectedQuality := (1-mutationAdaptationRate)*initialQuality + mutationAdaptationRate*1
+	if math.Abs(mutationWeights.qualities[0]-expectedQuality) > math.Pow(10, -9) {
+		t.Fatalf("quality of the mutation operator is %v, want %v", mutationWeights.qualities[0], expectedQuality)
+	}
+	for i, quality := range mutationWeights.qualities {
+		if quality > 1 {
+			t.Fatalf("quality of mutation operator %d is %v, more than 1", i, quality)
+		}
+	}
+}
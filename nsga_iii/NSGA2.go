@@ -51,6 +51,9 @@ func (n NSGA2) performFastNonDominatedSort(population Population) Fronts {
 	return fronts
 }
 
+// computeCrowdingDistance sums, over the objectives, the normalized distance between the
+// neighbours of every individual of the front. The extreme individuals of every objective
+// are always kept, and objectives on which the whole front agrees do not contribute.
 func (n NSGA2) computeCrowdingDistance(front Front) {
 	if len(front) == 0 {
 		return
@@ -59,25 +62,78 @@ func (n NSGA2) computeCrowdingDistance(front Front) {
 	for _, individual := range front {
 		individual.CrowdingDistance = 0
 	}
-	//fmt.Println("fnt lenght " , len(front))
 	for i := 0; i < len(front[0].ObjectiveValues); i++ {
-		sort.Slice(front, func(indexOfFirst, indexOfSecond int) bool {
-			return front[indexOfFirst].ObjectiveValues[i] > front[indexOfSecond].ObjectiveValues[i]
+		sort.SliceStable(front, func(indexOfFirst, indexOfSecond int) bool {
+			return front[indexOfFirst].ObjectiveValues[i] < front[indexOfSecond].ObjectiveValues[i]
 		})
 		front[0].CrowdingDistance = math.MaxFloat64
 		front[len(front)-1].CrowdingDistance = math.MaxFloat64
 
+		objectiveRange := front[len(front)-1].ObjectiveValues[i] - front[0].ObjectiveValues[i]
+		if objectiveRange == 0 {
+			continue
+		}
 		for j := 1; j < len(front)-1; j++ {
+			if front[j].CrowdingDistance == math.MaxFloat64 {
+				continue
+			}
 			front[j].CrowdingDistance = front[j].CrowdingDistance + ((front[j+1].ObjectiveValues[i] - front[j-1].ObjectiveValues[i]) /
-				(front[0].ObjectiveValues[i] - front[len(front)-1].ObjectiveValues[i]))
+				objectiveRange)
 		}
 
 	}
 
 }
 
+// sortFront orders the front by the crowded comparison operator, best individual first.
 func (n NSGA2) sortFront(front Front) {
-	sort.Slice(front, func(i, j int) bool {
-		return !front[i].crowdedComparisonOperatorLess(*front[j])
+	sort.SliceStable(front, func(i, j int) bool {
+		return front[i].crowdedComparisonOperatorLess(*front[j])
 	})
 }
+
+// GenerateNextPopulation creates the offspring of the parent population by crowded binary
+// tournament selection and keeps the best fronts of the parents and the offspring, truncating
+// the last front that does not fit by crowding distance.
+func (n NSGA2) GenerateNextPopulation(g GeneticAlgorithm, parentPopulation Population) Population {
+	newPopulation := g.makeNewPopulationBySelection(parentPopulation, g.binaryTormentSelection)
+	unionOfParentAndNewPopulations := g.combinePopulation(parentPopulation, newPopulation)
+	if g.NormalizeConstraintViolations {
+		normalizeConstraintViolations(unionOfParentAndNewPopulations)
+	}
+
+	fronts := g.nonDominatedSorter().Sort(unionOfParentAndNewPopulations)
+	nextPopulation := make(Population, 0, g.PopulationSize)
+	for _, front := range fronts {
+		if len(nextPopulation) == g.PopulationSize {
+			break
+		}
+		n.computeCrowdingDistance(*front)
+		if len(nextPopulation)+len(*front) > g.PopulationSize {
+			n.sortFront(*front)
+			nextPopulation = append(nextPopulation, (*front)[:g.PopulationSize-len(nextPopulation)]...)
+		} else {
+			nextPopulation = append(nextPopulation, *front...)
+		}
+	}
+	return nextPopulation
+}
+
+func (n NSGA2) Initialize(g GeneticAlgorithm, population Population) error {
+	// the ranks and crowding distances of the initial population drive the first tournaments
+	for _, front := range g.nonDominatedSorter().Sort(population) {
+		n.computeCrowdingDistance(*front)
+	}
+	return nil
+}
+
+func (n NSGA2) NextPopulation(g GeneticAlgorithm, generation int, parentPopulation Population) Population {
+	return n.GenerateNextPopulation(g, parentPopulation)
+}
+
+// RunNSGA2 runs NSGA-II, whose environmental selection relies on the crowding distance
+// instead of the reference points of NSGA-III. It returns the error of Run when the
+// configuration is rejected.
+func (g GeneticAlgorithm) RunNSGA2() (Population, error) {
+	return g.Run(NSGA2{})
+}
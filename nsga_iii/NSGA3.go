@@ -2,13 +2,170 @@ package nsga_iii
 
 import (
 	"fmt"
-	"github.com/rs/xid"
+	"math"
 	"strconv"
 )
 
 var nsga2 NSGA2
 
 type NSGA3 struct {
+	// NumberOfSegments divides every objective axis to place the reference points.
+	NumberOfSegments int
+	// NumberOfInsideSegments adds an inside layer of reference points, placed like the
+	// boundary layer of NumberOfInsideSegments segments and shrunk halfway towards the
+	// center of the simplex, when positive. Two layers with few segments each cover many
+	// objectives with interior reference points and a moderate number of points.
+	NumberOfInsideSegments int
+	// Adaptive relocates the reference points without any associated individual next to the
+	// crowded ones after every generation, as in A-NSGA-III.
+	Adaptive bool
+	// PreferencePoints restrict the reference points to neighborhoods of the given aspiration
+	// points or weight vectors, as in R-NSGA-III, to focus the search on regions of interest.
+	// Every neighborhood holds as many reference points as the layers above.
+	PreferencePoints []PreferencePoint
+	// PreferenceRadius is the size of the neighborhoods of the preference points relative to
+	// the whole simplex, 0.2 if not positive.
+	PreferenceRadius float64
+
+	// referencePoints are generated once per run and only change when they are adapted or
+	// placed around aspiration points; niche counts are computed on copies every generation
+	referencePoints              []*ReferencePoint
+	addedReferencePointIDs       map[string]bool
+	numberOfAddedReferencePoints int
+	nicheCountHistory            []map[string]int
+	normalizer                   *Normalizer
+}
+
+func (nsga3 *NSGA3) Initialize(g GeneticAlgorithm, population Population) error {
+	if nsga3.NumberOfSegments < 1 {
+		return fmt.Errorf("NSGA-III needs at least one segment per objective axis, got %d", nsga3.NumberOfSegments)
+	}
+	referencePoints := nsga3.generateReferencePoints(len(population[0].ObjectiveValues))
+	numberOfReferencePoints := len(referencePoints)
+	if len(nsga3.PreferencePoints) != 0 {
+		if nsga3.Adaptive {
+			return fmt.Errorf("NSGA-III cannot adapt the reference points of preference points")
+		}
+		for _, preferencePoint := range nsga3.PreferencePoints {
+			if err := preferencePoint.validate(len(population[0].ObjectiveValues)); err != nil {
+				return err
+			}
+		}
+		numberOfReferencePoints *= len(nsga3.PreferencePoints)
+	}
+	if g.PopulationSize < numberOfReferencePoints {
+		return fmt.Errorf("the population size %d is smaller than the %d reference points, NSGA-III suggests a population size of %d",
+			g.PopulationSize, numberOfReferencePoints, SuggestedPopulationSize(numberOfReferencePoints))
+	}
+
+	nsga3.normalizer = &Normalizer{}
+	nsga3.referencePoints = referencePoints
+	if len(nsga3.PreferencePoints) != 0 {
+		nsga3.referencePoints = nsga3.generatePreferenceReferencePoints(population)
+	}
+	nsga3.addedReferencePointIDs = map[string]bool{}
+	nsga3.numberOfAddedReferencePoints = 0
+	nsga3.nicheCountHistory = nil
+	return nil
+}
+
+// SuggestedPopulationSize returns the population size NSGA-III suggests for the number of
+// reference points: the smallest multiple of four greater than it.
+func SuggestedPopulationSize(numberOfReferencePoints int) int {
+	return (numberOfReferencePoints/4 + 1) * 4
+}
+
+// generateReferencePoints returns the boundary layer of reference points followed by the
+// inside layer, if there is one, without the inside points coinciding with boundary points.
+func (nsga3 NSGA3) generateReferencePoints(numberOfObjectiveFunctions int) []*ReferencePoint {
+	referencePoints := nsga3.GetReferencePoints(numberOfObjectiveFunctions, nsga3.NumberOfSegments)
+	if nsga3.NumberOfInsideSegments < 1 {
+		return referencePoints
+	}
+
+	numberOfBoundaryReferencePoints := len(referencePoints)
+	for i, insideReferencePoint := range nsga3.GetReferencePoints(numberOfObjectiveFunctions, nsga3.NumberOfInsideSegments) {
+		for j := range insideReferencePoint.Coordinates {
+			insideReferencePoint.Coordinates[j] = (insideReferencePoint.Coordinates[j] + 1.0/float64(numberOfObjectiveFunctions)) / 2
+		}
+		isDuplicate := false
+		for _, boundaryReferencePoint := range referencePoints[:numberOfBoundaryReferencePoints] {
+			if euclideanDistance(boundaryReferencePoint.Coordinates, insideReferencePoint.Coordinates) < math.Pow(10, -9) {
+				isDuplicate = true
+			}
+		}
+		if !isDuplicate {
+			insideReferencePoint.ID = "inside-reference-point-" + strconv.Itoa(i)
+			referencePoints = append(referencePoints, insideReferencePoint)
+		}
+	}
+	return referencePoints
+}
+
+func (nsga3 *NSGA3) NextPopulation(g GeneticAlgorithm, generation int, parentPopulation Population) Population {
+	if len(nsga3.PreferencePoints) != 0 {
+		// aspiration points move with the normalization of the population, their reference points keep their IDs
+		nsga3.referencePoints = nsga3.generatePreferenceReferencePoints(parentPopulation)
+	}
+	referencePoints := make([]*ReferencePoint, len(nsga3.referencePoints))
+	for i, referencePoint := range nsga3.referencePoints {
+		referencePoints[i] = &ReferencePoint{ID: referencePoint.ID, Coordinates: referencePoint.Coordinates}
+	}
+	nextPopulation := nsga3.GenerateNextPopulation(generation, g, parentPopulation, referencePoints)
+
+	nicheCounts := nsga3.computeNicheCounts(nextPopulation, nsga3.referencePoints)
+	if nsga3.Adaptive {
+		nicheCounts = nsga3.adaptReferencePoints(nextPopulation, nicheCounts)
+	}
+	nsga3.nicheCountHistory = append(nsga3.nicheCountHistory, nicheCounts)
+	for _, individual := range nextPopulation {
+		individual.ReferencePoint = ReferencePoint{}
+		individual.PerpendicularDistance = 0
+	}
+	return nextPopulation
+}
+
+// ReferencePoints returns the reference points of the last generation, with the number of
+// individuals of the population associated with them as their niche counts.
+func (nsga3 *NSGA3) ReferencePoints() []ReferencePoint {
+	var nicheCounts map[string]int
+	if len(nsga3.nicheCountHistory) != 0 {
+		nicheCounts = nsga3.nicheCountHistory[len(nsga3.nicheCountHistory)-1]
+	}
+	referencePoints := make([]ReferencePoint, len(nsga3.referencePoints))
+	for i, referencePoint := range nsga3.referencePoints {
+		referencePoints[i] = ReferencePoint{
+			ID:          referencePoint.ID,
+			Coordinates: append([]float64{}, referencePoint.Coordinates...),
+			NicheCount:  nicheCounts[referencePoint.ID],
+		}
+	}
+	return referencePoints
+}
+
+// NicheCountHistory returns, for every generation of the run, the number of individuals of
+// the population associated with every reference point by reference point ID.
+func (nsga3 *NSGA3) NicheCountHistory() []map[string]int {
+	return nsga3.nicheCountHistory
+}
+
+// Normalizer returns the normalizer of the run, which holds the ideal and the nadir point
+// of the last population normalized.
+func (nsga3 *NSGA3) Normalizer() *Normalizer {
+	return nsga3.normalizer
+}
+
+// runNormalizer returns the normalizer of the run, which tracks the ideal and the extreme
+// points across generations, or a new one outside of a run.
+func (nsga3 NSGA3) runNormalizer() *Normalizer {
+	if nsga3.normalizer == nil {
+		return &Normalizer{}
+	}
+	return nsga3.normalizer
+}
+
+func (nsga3 NSGA3) normalize(population Population) {
+	nsga3.runNormalizer().Normalize(population)
 }
 
 func (nsga3 NSGA3) GenerateNextPopulation(t int, g GeneticAlgorithm, parentPopulation Population, referencePoints []*ReferencePoint) Population {
@@ -21,9 +178,12 @@ func (nsga3 NSGA3) GenerateNextPopulation(t int, g GeneticAlgorithm, parentPopul
 	newPopulation := g.makeNewPopulation(parentPopulation, true)
 	//[ALGORITHM-1]STEP-3
 	unionOfParentAndNewPopulations := g.combinePopulation(parentPopulation, newPopulation)
+	if g.NormalizeConstraintViolations {
+		normalizeConstraintViolations(unionOfParentAndNewPopulations)
+	}
 
 	//[ALGORITHM-1]STEP-4
-	fronts := nsga2.performFastNonDominatedSort(unionOfParentAndNewPopulations)
+	fronts := g.nonDominatedSorter().Sort(unionOfParentAndNewPopulations)
 	//[ALGORITHM-1]STEP-5,6,7
 
 	for ok := true; ok; ok = len(temporaryNextPopulation) <= g.PopulationSize {
@@ -43,14 +203,14 @@ func (nsga3 NSGA3) GenerateNextPopulation(t int, g GeneticAlgorithm, parentPopul
 		//[ALGORITHM-1]STEP-13
 		numberOfRemainingIndividuals := g.PopulationSize - len(nextPopulation)
 		//[ALGORITHM-1]STEP-14
-		Normalize(temporaryNextPopulation)
+		nsga3.normalize(temporaryNextPopulation)
 		//[ALGORITHM-1]STEP-15
 		Associate(temporaryNextPopulation, referencePoints)
 		//[ALGORITHM-1]STEP-16
 		nsga3.computeNicheCountForEachReferencePoint(nextPopulation, referencePoints)
 		//fmt.Println("")
 		//[ALGORITHM-1]STEP-17
-		Niching(numberOfRemainingIndividuals, &temporaryNextPopulation, referencePoints, lastFront, &nextPopulation)
+		Niching(numberOfRemainingIndividuals, &temporaryNextPopulation, referencePoints, lastFront, &nextPopulation, g.random())
 	}
 	return nextPopulation
 }
@@ -81,45 +241,58 @@ func (nsga3 NSGA3) computeNicheCountForEachReferencePoint(temporaryPopulation Po
 	}
 }
 
-var referencePointsCoordinates map[string]ReferencePoint
-
+// GetReferencePoints returns the Das and Dennis reference points of numberOfSegments
+// segments per objective axis, whose coordinates are the compositions of numberOfSegments
+// into numberOfObjectiveFunctions non-negative integers divided by numberOfSegments. The
+// points are ordered lexicographically from the first objective axis downwards.
 func (nsga3 NSGA3) GetReferencePoints(numberOfObjectiveFunctions int, numberOfSegments int) []*ReferencePoint {
-	referencePointsCoordinates = map[string]ReferencePoint{}
-	initialReferencePointCoordination := make([]float64, numberOfObjectiveFunctions)
-	initialReferencePointCoordination[0] = 1.0
-	initialReferencePoint := ReferencePoint{Coordinates: initialReferencePointCoordination}
-	nsga3.generateReferencePointCoordinatesRecursively(initialReferencePoint, numberOfObjectiveFunctions, numberOfSegments)
-
-	var referencePointsToReturn []*ReferencePoint
-	for _, referencePointCoordinate := range referencePointsCoordinates {
-		guid := xid.New()
-		referencePointsToReturn = append(referencePointsToReturn, &ReferencePoint{ID: guid.String(), Coordinates: referencePointCoordinate.Coordinates})
+	if numberOfObjectiveFunctions < 1 || numberOfSegments < 1 {
+		return nil
 	}
-
-	return referencePointsToReturn
+	referencePoints := make([]*ReferencePoint, 0, NumberOfReferencePoints(numberOfObjectiveFunctions, numberOfSegments))
+	composition := make([]int, numberOfObjectiveFunctions)
+	var generateCompositions func(indexOfObjectiveFunction int, remainingSegments int)
+	generateCompositions = func(indexOfObjectiveFunction int, remainingSegments int) {
+		if indexOfObjectiveFunction == numberOfObjectiveFunctions-1 {
+			composition[indexOfObjectiveFunction] = remainingSegments
+			coordinates := make([]float64, numberOfObjectiveFunctions)
+			for i, numberOfSegmentsOfAxis := range composition {
+				coordinates[i] = float64(numberOfSegmentsOfAxis) / float64(numberOfSegments)
+			}
+			referencePoints = append(referencePoints, &ReferencePoint{
+				ID:          "reference-point-" + strconv.Itoa(len(referencePoints)),
+				Coordinates: coordinates,
+			})
+			return
+		}
+		for numberOfSegmentsOfAxis := remainingSegments; numberOfSegmentsOfAxis >= 0; numberOfSegmentsOfAxis-- {
+			composition[indexOfObjectiveFunction] = numberOfSegmentsOfAxis
+			generateCompositions(indexOfObjectiveFunction+1, remainingSegments-numberOfSegmentsOfAxis)
+		}
+	}
+	generateCompositions(0, numberOfSegments)
+	return referencePoints
 }
-func (nsga3 NSGA3) generateReferencePointCoordinatesRecursively(referencePoint ReferencePoint, numberOfObjectiveFunctions int, numberOfSegments int) int {
-	if referencePoint.Coordinates[0] < 0 {
+
+// NumberOfReferencePoints returns the number of Das and Dennis reference points of
+// numberOfSegments segments per objective axis, the binomial coefficient
+// C(numberOfSegments+numberOfObjectiveFunctions-1, numberOfObjectiveFunctions-1).
+func NumberOfReferencePoints(numberOfObjectiveFunctions int, numberOfSegments int) int {
+	if numberOfObjectiveFunctions < 1 || numberOfSegments < 1 {
 		return 0
 	}
-	referencePointsCoordinates[fmt.Sprint(referencePoint.Coordinates)] = referencePoint
-
+	numberOfReferencePoints := 1
 	for i := 1; i < numberOfObjectiveFunctions; i++ {
-		newCoordinates := make([]float64, len(referencePoint.Coordinates))
-		copy(newCoordinates, referencePoint.Coordinates)
-		newCoordinates[0] = newCoordinates[0] - nsga3.Round(1.0/float64(numberOfSegments))
-		newCoordinates[i] = newCoordinates[i] + nsga3.Round(1.0/float64(numberOfSegments))
-		if _, exists := referencePointsCoordinates[fmt.Sprint(newCoordinates)]; !exists {
-			newReferencePoint := ReferencePoint{Coordinates: newCoordinates}
-			nsga3.generateReferencePointCoordinatesRecursively(newReferencePoint, numberOfObjectiveFunctions, numberOfSegments)
-		}
+		numberOfReferencePoints = numberOfReferencePoints * (numberOfSegments + i) / i
 	}
-
-	return 0
-
+	return numberOfReferencePoints
 }
 
-func (nsga3 NSGA3) Round(number float64) float64 {
-	float, _ := strconv.ParseFloat(fmt.Sprintf("%.2f", number), 64)
-	return float
-}
\ No newline at end of file
+func lexicographicallyGreater(coordinates []float64, anotherCoordinates []float64) bool {
+	for i := range coordinates {
+		if coordinates[i] != anotherCoordinates[i] {
+			return coordinates[i] > anotherCoordinates[i]
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package nsga_iii
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestGetReferencePoints(t *testing.T) {
+	tests := []struct {
+		numberOfObjectiveFunctions int
+		numberOfSegments           int
+	}{
+		{numberOfObjectiveFunctions: 1, numberOfSegments: 4},
+		{numberOfObjectiveFunctions: 2, numberOfSegments: 1},
+		{numberOfObjectiveFunctions: 2, numberOfSegments: 3},
+		{numberOfObjectiveFunctions: 3, numberOfSegments: 3},
+		{numberOfObjectiveFunctions: 3, numberOfSegments: 7},
+		{numberOfObjectiveFunctions: 4, numberOfSegments: 4},
+		{numberOfObjectiveFunctions: 5, numberOfSegments: 7},
+		{numberOfObjectiveFunctions: 8, numberOfSegments: 3},
+		{numberOfObjectiveFunctions: 2, numberOfSegments: 150},
+		{numberOfObjectiveFunctions: 3, numberOfSegments: 150},
+	}
+	for _, test := range tests {
+		M, H := test.numberOfObjectiveFunctions, test.numberOfSegments
+		t.Run(fmt.Sprintf("M=%d H=%d", M, H), func(t *testing.T) {
+			referencePoints := NSGA3{}.GetReferencePoints(M, H)
+
+			expectedNumberOfReferencePoints := int(new(big.Int).Binomial(int64(H+M-1), int64(M-1)).Int64())
+			if NumberOfReferencePoints(M, H) != expectedNumberOfReferencePoints {
+				t.Errorf("NumberOfReferencePoints is %d, want %d", NumberOfReferencePoints(M, H), expectedNumberOfReferencePoints)
+			}
+			if len(referencePoints) != expectedNumberOfReferencePoints {
+				t.Fatalf("got %d reference points, want %d", len(referencePoints), expectedNumberOfReferencePoints)
+			}
+
+			isGenerated := make(map[string]bool, len(referencePoints))
+			for i, referencePoint := range referencePoints {
+				if len(referencePoint.Coordinates) != M {
+					t.Fatalf("reference point %d has %d coordinates, want %d", i, len(referencePoint.Coordinates), M)
+				}
+				sum := 0.0
+				for _, coordinate := range referencePoint.Coordinates {
+					if coordinate < 0 {
+						t.Fatalf("reference point %d has a negative coordinate: %v", i, referencePoint.Coordinates)
+					}
+					sum += coordinate
+				}
+				if math.Abs(sum-1) > math.Pow(10, -9) {
+					t.Fatalf("coordinates of reference point %d sum up to %v: %v", i, sum, referencePoint.Coordinates)
+				}
+				key := fmt.Sprint(referencePoint.Coordinates)
+				if isGenerated[key] {
+					t.Fatalf("reference point %d duplicates %v", i, referencePoint.Coordinates)
+				}
+				isGenerated[key] = true
+				if i > 0 && !lexicographicallyGreater(referencePoints[i-1].Coordinates, referencePoint.Coordinates) {
+					t.Fatalf("reference point %d %v is not ordered after %v", i, referencePoint.Coordinates, referencePoints[i-1].Coordinates)
+				}
+				if referencePoint.ID != fmt.Sprint("reference-point-", i) {
+					t.Fatalf("reference point %d has ID %q", i, referencePoint.ID)
+				}
+			}
+
+			for i, referencePoint := range (NSGA3{}).GetReferencePoints(M, H) {
+				if referencePoint.ID != referencePoints[i].ID || fmt.Sprint(referencePoint.Coordinates) != fmt.Sprint(referencePoints[i].Coordinates) {
+					t.Fatalf("reference point %d is %v in a second call, was %v", i, referencePoint.Coordinates, referencePoints[i].Coordinates)
+				}
+			}
+		})
+	}
+}
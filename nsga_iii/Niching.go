@@ -5,7 +5,9 @@ import (
 	"math/rand"
 )
 
-func Niching(numberOfRemainingIndividuals int, temporaryPopulation *Population, referencePoints []*ReferencePoint, lastFront *Front, incompleteNextPopulation *Population){
+func Niching(numberOfRemainingIndividuals int, temporaryPopulation *Population, referencePoints []*ReferencePoint, lastFront *Front, incompleteNextPopulation *Population, random *rand.Rand){
+	// reference points without last front members are removed from a copy, the caller's slice is kept intact
+	referencePoints = append([]*ReferencePoint{}, referencePoints...)
 	k := 0
 	sum := 0
 	for _, ref := range referencePoints{
@@ -14,7 +16,7 @@ func Niching(numberOfRemainingIndividuals int, temporaryPopulation *Population,
 
 	for k<numberOfRemainingIndividuals{
 		referencePointsMinNicheCount := findReferencePointsWithMinNicheCount(referencePoints)
-		randomReferencePointWithMinNicheCount := referencePointsMinNicheCount[rand.Intn(len(referencePointsMinNicheCount))]
+		randomReferencePointWithMinNicheCount := referencePointsMinNicheCount[random.Intn(len(referencePointsMinNicheCount))]
 		individualsBelongToMinReferencePointAndLastFront := findIndividualsBelongToMinReferencePointAndLastFront(temporaryPopulation, *lastFront, *randomReferencePointWithMinNicheCount)
 
 		if len(individualsBelongToMinReferencePointAndLastFront) != 0{
@@ -23,7 +25,7 @@ func Niching(numberOfRemainingIndividuals int, temporaryPopulation *Population,
 				individual = getIndividualWithMinPerpendicularDistance(individualsBelongToMinReferencePointAndLastFront)
 				*incompleteNextPopulation = append(*incompleteNextPopulation, individual)
 			}else{
-				individual = individualsBelongToMinReferencePointAndLastFront[rand.Intn(len(individualsBelongToMinReferencePointAndLastFront))]
+				individual = individualsBelongToMinReferencePointAndLastFront[random.Intn(len(individualsBelongToMinReferencePointAndLastFront))]
 				*incompleteNextPopulation = append(*incompleteNextPopulation, individual)
 			}
 			randomReferencePointWithMinNicheCount.NicheCount++
@@ -0,0 +1,149 @@
+package nsga_iii
+
+import (
+	"strconv"
+)
+
+type NodeSelectorOperator string
+
+const (
+	NodeSelectorOpIn           NodeSelectorOperator = "In"
+	NodeSelectorOpNotIn        NodeSelectorOperator = "NotIn"
+	NodeSelectorOpExists       NodeSelectorOperator = "Exists"
+	NodeSelectorOpDoesNotExist NodeSelectorOperator = "DoesNotExist"
+	NodeSelectorOpGt           NodeSelectorOperator = "Gt"
+	NodeSelectorOpLt           NodeSelectorOperator = "Lt"
+)
+
+// NodeSelectorRequirement matches nodes by the value of the label Key.
+// NotIn and DoesNotExist express anti-affinity to nodes.
+type NodeSelectorRequirement struct {
+	Key      string
+	Operator NodeSelectorOperator
+	Values   []string
+}
+
+// NodeSelectorTerm matches a node when all of its requirements match.
+type NodeSelectorTerm struct {
+	MatchExpressions []NodeSelectorRequirement
+}
+
+type PreferredNodeSelectorTerm struct {
+	Weight     float64
+	Preference NodeSelectorTerm
+}
+
+// NodeAffinity restricts the nodes a task can run on. A task must be placed on a
+// node matching at least one of the Required terms, if there are any, and prefers
+// nodes matching the Preferred terms.
+type NodeAffinity struct {
+	Required  []NodeSelectorTerm
+	Preferred []PreferredNodeSelectorTerm
+}
+
+const (
+	NodeAffinityConstraintName          = "node-affinity"
+	NodeAffinityPreferenceObjectiveName = "node-affinity-preference"
+)
+
+func (requirement NodeSelectorRequirement) matches(node Node) bool {
+	value, exists := node.Labels[requirement.Key]
+	switch requirement.Operator {
+	case NodeSelectorOpIn:
+		return exists && containsString(requirement.Values, value)
+	case NodeSelectorOpNotIn:
+		return !exists || !containsString(requirement.Values, value)
+	case NodeSelectorOpExists:
+		return exists
+	case NodeSelectorOpDoesNotExist:
+		return !exists
+	case NodeSelectorOpGt, NodeSelectorOpLt:
+		if !exists || len(requirement.Values) != 1 {
+			return false
+		}
+		nodeValue, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return false
+		}
+		requirementValue, err := strconv.ParseFloat(requirement.Values[0], 64)
+		if err != nil {
+			return false
+		}
+		if requirement.Operator == NodeSelectorOpGt {
+			return nodeValue > requirementValue
+		}
+		return nodeValue < requirementValue
+	}
+	return false
+}
+
+func (term NodeSelectorTerm) matches(node Node) bool {
+	for _, requirement := range term.MatchExpressions {
+		if !requirement.matches(node) {
+			return false
+		}
+	}
+	return true
+}
+
+// allowsNode reports whether the required node affinity of the task is satisfied by the node.
+func (task Task) allowsNode(node Node) bool {
+	if len(task.NodeAffinity.Required) == 0 {
+		return true
+	}
+	for _, term := range task.NodeAffinity.Required {
+		if term.matches(node) {
+			return true
+		}
+	}
+	return false
+}
+
+// unmatchedNodeAffinityPreference sums the weights of the preferred terms the node does not match.
+func (task Task) unmatchedNodeAffinityPreference(node Node) float64 {
+	unmatchedWeight := 0.0
+	for _, preferredTerm := range task.NodeAffinity.Preferred {
+		if !preferredTerm.Preference.matches(node) {
+			unmatchedWeight += preferredTerm.Weight
+		}
+	}
+	return unmatchedWeight
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// NodeAffinityConstraint is violated by every task placed on a node that does not
+// satisfy its required node affinity.
+type NodeAffinityConstraint struct{}
+
+func (constraint NodeAffinityConstraint) Name() string {
+	return NodeAffinityConstraintName
+}
+
+func (constraint NodeAffinityConstraint) Violation(individual *Individual) float64 {
+	violation := 0.0
+	for t, nodeIndex := range individual.Genome {
+		if nodeIndex != Unassigned && !individual.Problem.allowsNode(t, nodeIndex) {
+			violation++
+		}
+	}
+	return violation
+}
+
+func (individual *Individual) computeNodeAffinityPreferenceObjectiveFunction() float64 {
+	problem := individual.Problem
+	nodeAffinityPreferenceObjectiveValue := 0.0
+	for _, t := range problem.tasksWithPreferredNodeAffinity {
+		if nodeIndex := individual.Genome[t]; nodeIndex != Unassigned {
+			nodeAffinityPreferenceObjectiveValue += problem.Tasks[t].unmatchedNodeAffinityPreference(problem.Nodes[nodeIndex])
+		}
+	}
+	return nodeAffinityPreferenceObjectiveValue
+}
@@ -0,0 +1,100 @@
+package nsga_iii
+
+import (
+	"sort"
+)
+
+// NonDominatedSorter partitions a population into fronts by constrained domination
+// and sets the Rank of every individual to the index of its front. Like the fast
+// non-dominated sort, the returned fronts end with an empty front.
+type NonDominatedSorter interface {
+	Sort(population Population) Fronts
+}
+
+// FastNonDominatedSorter is the pairwise O(MN^2) sort of NSGA-II, which records the
+// individuals dominated by every individual on the individual itself.
+type FastNonDominatedSorter struct{}
+
+func (sorter FastNonDominatedSorter) Sort(population Population) Fronts {
+	for _, individual := range population {
+		individual.IndividualsDominatedByThis = nil
+		individual.NumberOfIndividualsDominateThis = 0
+		individual.Rank = 0
+	}
+	return nsga2.performFastNonDominatedSort(population)
+}
+
+// EfficientNonDominatedSorter is the efficient non-dominated sort with sequential search
+// (ENS-SS). Individuals are visited in an order in which no individual is dominated by a
+// later one, so each of them only has to be compared with the fronts built so far. It
+// keeps no state on the individuals and produces the same fronts as the fast
+// non-dominated sort, with the individuals of every front in population order.
+type EfficientNonDominatedSorter struct{}
+
+func (sorter EfficientNonDominatedSorter) Sort(population Population) Fronts {
+	order := make([]int, len(population))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return precedesInConstrainedDominationOrder(population[order[i]], population[order[j]])
+	})
+
+	var indexesOfFronts [][]int
+	for _, index := range order {
+		rank := 0
+		for rank < len(indexesOfFronts) && isDominatedByAnyOf(population[index], population, indexesOfFronts[rank]) {
+			rank++
+		}
+		if rank == len(indexesOfFronts) {
+			indexesOfFronts = append(indexesOfFronts, nil)
+		}
+		indexesOfFronts[rank] = append(indexesOfFronts[rank], index)
+	}
+
+	fronts := make(Fronts, 0, len(indexesOfFronts)+1)
+	for rank, indexes := range indexesOfFronts {
+		sort.Ints(indexes)
+		front := make(Front, len(indexes))
+		for i, index := range indexes {
+			population[index].Rank = rank
+			front[i] = population[index]
+		}
+		fronts = append(fronts, &front)
+	}
+	return append(fronts, &Front{})
+}
+
+// precedesInConstrainedDominationOrder orders feasible individuals before infeasible ones,
+// infeasible ones by increasing constraint violation and feasible ones lexicographically
+// by their objective values, so that an individual never precedes one dominating it.
+func precedesInConstrainedDominationOrder(individual *Individual, anotherIndividual *Individual) bool {
+	if individual.IsFeasible != anotherIndividual.IsFeasible {
+		return individual.IsFeasible
+	}
+	if !individual.IsFeasible {
+		return individual.ConstrainedViolationValue < anotherIndividual.ConstrainedViolationValue
+	}
+	for i := range individual.ObjectiveValues {
+		if individual.ObjectiveValues[i] != anotherIndividual.ObjectiveValues[i] {
+			return individual.ObjectiveValues[i] < anotherIndividual.ObjectiveValues[i]
+		}
+	}
+	return false
+}
+
+func isDominatedByAnyOf(individual *Individual, population Population, indexesOfFront []int) bool {
+	for _, index := range indexesOfFront {
+		if population[index].constraintDominate(*individual) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g GeneticAlgorithm) nonDominatedSorter() NonDominatedSorter {
+	if g.NonDominatedSorter == nil {
+		return FastNonDominatedSorter{}
+	}
+	return g.NonDominatedSorter
+}
@@ -0,0 +1,130 @@
+package nsga_iii
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+type sortedIndividual struct {
+	objectiveValues           []float64
+	constrainedViolationValue float64
+}
+
+func newSortedPopulation(individuals []sortedIndividual) Population {
+	population := make(Population, len(individuals))
+	for i, individual := range individuals {
+		population[i] = &Individual{
+			ID:                        strconv.Itoa(i),
+			ObjectiveValues:           append([]float64{}, individual.objectiveValues...),
+			IsFeasible:                individual.constrainedViolationValue == 0,
+			ConstrainedViolationValue: individual.constrainedViolationValue,
+		}
+	}
+	return population
+}
+
+func randomSortedIndividuals(random *rand.Rand, numberOfIndividuals int, numberOfObjectiveFunctions int) []sortedIndividual {
+	individuals := make([]sortedIndividual, numberOfIndividuals)
+	for i := range individuals {
+		individuals[i].objectiveValues = make([]float64, numberOfObjectiveFunctions)
+		for k := range individuals[i].objectiveValues {
+			// few distinct values, so that many individuals tie on some objectives
+			individuals[i].objectiveValues[k] = float64(random.Intn(5))
+		}
+		if random.Intn(3) == 0 {
+			individuals[i].constrainedViolationValue = float64(1 + random.Intn(3))
+		}
+	}
+	return individuals
+}
+
+// idsOfFronts returns the IDs of the individuals of every front, sorted within the front.
+func idsOfFronts(fronts Fronts) [][]string {
+	ids := make([][]string, len(fronts))
+	for i, front := range fronts {
+		for _, individual := range *front {
+			ids[i] = append(ids[i], individual.ID)
+		}
+		sort.Strings(ids[i])
+	}
+	return ids
+}
+
+func TestEfficientNonDominatedSorterMatchesFastNonDominatedSorter(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	tests := []struct {
+		name        string
+		individuals []sortedIndividual
+	}{
+		{
+			name: "single individual",
+			individuals: []sortedIndividual{
+				{objectiveValues: []float64{1, 2}},
+			},
+		},
+		{
+			name: "chain",
+			individuals: []sortedIndividual{
+				{objectiveValues: []float64{3, 3}},
+				{objectiveValues: []float64{1, 1}},
+				{objectiveValues: []float64{2, 2}},
+			},
+		},
+		{
+			name: "trade-off",
+			individuals: []sortedIndividual{
+				{objectiveValues: []float64{1, 4}},
+				{objectiveValues: []float64{4, 1}},
+				{objectiveValues: []float64{2, 2}},
+				{objectiveValues: []float64{3, 3}},
+			},
+		},
+		{
+			name: "ties",
+			individuals: []sortedIndividual{
+				{objectiveValues: []float64{1, 2, 3}},
+				{objectiveValues: []float64{1, 2, 3}},
+				{objectiveValues: []float64{1, 3, 3}},
+				{objectiveValues: []float64{1, 3, 3}},
+				{objectiveValues: []float64{2, 2, 2}},
+				{objectiveValues: []float64{1, 2, 3}},
+			},
+		},
+		{
+			name: "infeasible with equal violation",
+			individuals: []sortedIndividual{
+				{objectiveValues: []float64{5, 5}, constrainedViolationValue: 2},
+				{objectiveValues: []float64{1, 1}, constrainedViolationValue: 2},
+				{objectiveValues: []float64{9, 9}},
+				{objectiveValues: []float64{3, 0}, constrainedViolationValue: 1},
+				{objectiveValues: []float64{0, 3}, constrainedViolationValue: 2},
+				{objectiveValues: []float64{1, 1}, constrainedViolationValue: 1},
+			},
+		},
+		{
+			name:        "random with two objectives",
+			individuals: randomSortedIndividuals(random, 60, 2),
+		},
+		{
+			name:        "random with four objectives",
+			individuals: randomSortedIndividuals(random, 100, 4),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expectedFronts := idsOfFronts(FastNonDominatedSorter{}.Sort(newSortedPopulation(test.individuals)))
+			fronts := idsOfFronts(EfficientNonDominatedSorter{}.Sort(newSortedPopulation(test.individuals)))
+			if len(fronts) != len(expectedFronts) {
+				t.Fatalf("got %d fronts %v, want %d fronts %v", len(fronts), fronts, len(expectedFronts), expectedFronts)
+			}
+			for i := range fronts {
+				if !reflect.DeepEqual(fronts[i], expectedFronts[i]) {
+					t.Errorf("front %d is %v, want %v", i, fronts[i], expectedFronts[i])
+				}
+			}
+		})
+	}
+}
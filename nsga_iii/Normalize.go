@@ -5,79 +5,199 @@ import (
 	"errors"
 )
 
-func Normalize(populationWithOverflow Population){
-	numberOfObjectiveFunctions := len(populationWithOverflow[0].ObjectiveValues)
-	idealObjectivePoint := make([]float64, numberOfObjectiveFunctions)
-	extremeTranslatedObjectivePoints := make([][]float64, numberOfObjectiveFunctions)
-	extremeObjectivePoints := make([][]float64, numberOfObjectiveFunctions)
-	//[ALGORITHM-2]STEP-1
-	for indexOfObjectiveFunction := 0; indexOfObjectiveFunction < numberOfObjectiveFunctions; indexOfObjectiveFunction++{
-		//[ALGORITHM-2]STEP-2
-		idealValueOfObjectiveFunction := computeIdealObjectiveValue(populationWithOverflow, indexOfObjectiveFunction)
-		idealObjectivePoint[indexOfObjectiveFunction] = idealValueOfObjectiveFunction
+// normalization maps objective values to the normalized objective space of a population,
+// in which the ideal point is the origin and the nadir point is the point of ones.
+type normalization struct {
+	idealObjectivePoint []float64
+	nadirObjectivePoint []float64
+}
 
-		//[ALGORITHM-2]STEP-3
-		translateObjectiveFunction(populationWithOverflow, indexOfObjectiveFunction, idealValueOfObjectiveFunction)
+// Normalizer normalizes populations as in NSGA-III, with the improvements of Blank, Deb
+// and Roy: the ideal point and the extreme points are tracked across all the populations it
+// normalizes, and the nadir point falls back to the worst objective values of the
+// non-dominated individuals when the hyperplane through the extreme points is degenerate
+// or cuts an objective axis too close to the ideal point. The zero value is ready to use.
+type Normalizer struct {
+	// IdealPoint holds the smallest value of every objective over all populations normalized.
+	IdealPoint []float64
+	// NadirPoint holds the nadir point estimated for the last population normalized.
+	NadirPoint []float64
+	// IsNadirPointFromFront reports whether the nadir point of the last population normalized
+	// is the worst point of its non-dominated individuals rather than the hyperplane intercepts.
+	IsNadirPointFromFront bool
 
-		//[ALGORITHM-2]STEP-4
-	}
+	extremeObjectivePoints [][]float64
+}
 
+// minimumObjectiveRange is the smallest distance between the ideal and the nadir value of
+// an objective that is used to normalize it.
+const minimumObjectiveRange = 1e-6
 
+// Normalize normalizes the population on its own, without the points of earlier populations.
+func Normalize(populationWithOverflow Population){
+	(&Normalizer{}).Normalize(populationWithOverflow)
+}
+
+// Normalize translates the objective values of the population by the ideal point and
+// divides them by the distance between the ideal and the nadir point.
+func (normalizer *Normalizer) Normalize(population Population) {
+	normalization := normalizer.computeNormalization(population)
+	for _, individual := range population {
+		normalization.normalizeTranslatedObjectiveValues(individual.TranslatedObjectiveValues, individual.NormalizedObjectiveValues)
+	}
+}
+
+// computeNormalization updates the ideal point, the extreme points and the nadir point with
+// the population, translates the objective values of the population by the ideal point and
+// returns the normalization of the population.
+func (normalizer *Normalizer) computeNormalization(population Population) normalization {
+	numberOfObjectiveFunctions := len(population[0].ObjectiveValues)
+	//[ALGORITHM-2]STEP-2
+	if len(normalizer.IdealPoint) != numberOfObjectiveFunctions {
+		normalizer.IdealPoint = make([]float64, numberOfObjectiveFunctions)
+		for i := range normalizer.IdealPoint {
+			normalizer.IdealPoint[i] = math.Inf(1)
+		}
+		normalizer.extremeObjectivePoints = nil
+	}
 	for indexOfObjectiveFunction := 0; indexOfObjectiveFunction < numberOfObjectiveFunctions; indexOfObjectiveFunction++ {
-		extremeTranslatedObjectivePoints[indexOfObjectiveFunction] = computeTranslatedExtremeObjectivePoint(numberOfObjectiveFunctions, indexOfObjectiveFunction, populationWithOverflow)
+		normalizer.IdealPoint[indexOfObjectiveFunction] = math.Min(normalizer.IdealPoint[indexOfObjectiveFunction], computeIdealObjectiveValue(population, indexOfObjectiveFunction))
+		//[ALGORITHM-2]STEP-3
+		translateObjectiveFunction(population, indexOfObjectiveFunction, normalizer.IdealPoint[indexOfObjectiveFunction])
 	}
+
+	//[ALGORITHM-2]STEP-4
+	candidateObjectivePoints := append([][]float64{}, normalizer.extremeObjectivePoints...)
+	for _, individual := range population {
+		candidateObjectivePoints = append(candidateObjectivePoints, individual.ObjectiveValues)
+	}
+	extremeObjectivePoints := make([][]float64, numberOfObjectiveFunctions)
 	for indexOfObjectiveFunction := 0; indexOfObjectiveFunction < numberOfObjectiveFunctions; indexOfObjectiveFunction++ {
-		extremeObjectivePoints[indexOfObjectiveFunction] = computeExtremeObjectivePoint(numberOfObjectiveFunctions, indexOfObjectiveFunction, populationWithOverflow)
+		extremeObjectivePoints[indexOfObjectiveFunction] = append([]float64{}, computeExtremeObjectivePointOf(candidateObjectivePoints, normalizer.IdealPoint, indexOfObjectiveFunction)...)
 	}
+	normalizer.extremeObjectivePoints = extremeObjectivePoints
 
-	intercepts := ComputeIntercepts(extremeTranslatedObjectivePoints, numberOfObjectiveFunctions)
+	worstObjectivePoint := computeWorstObjectivePoint(population)
+	nadirPoint, isValid := computeNadirPointFromHyperplane(extremeObjectivePoints, normalizer.IdealPoint)
+	normalizer.IsNadirPointFromFront = !isValid
+	if isValid {
+		for i := range nadirPoint {
+			nadirPoint[i] = math.Min(nadirPoint[i], worstObjectivePoint[i])
+		}
+	} else {
+		nadirPoint = computeWorstObjectivePoint(nonDominatedIndividualsOf(population))
+	}
+	for i := range nadirPoint {
+		if nadirPoint[i]-normalizer.IdealPoint[i] <= minimumObjectiveRange {
+			nadirPoint[i] = worstObjectivePoint[i]
+		}
+	}
+	normalizer.NadirPoint = nadirPoint
+	return normalization{
+		idealObjectivePoint: append([]float64{}, normalizer.IdealPoint...),
+		nadirObjectivePoint: append([]float64{}, nadirPoint...),
+	}
+}
 
-	for _, individual := range populationWithOverflow {
-		for i := 0; i < numberOfObjectiveFunctions; i++ {
-			//************************************************************changed to intercept
-			if math.Abs(intercepts[i] - idealObjectivePoint[i]) > 10e-10{
-				individual.NormalizedObjectiveValues[i] = individual.TranslatedObjectiveValues[i]/ (intercepts[i] - idealObjectivePoint[i])
-			}else{
-				individual.NormalizedObjectiveValues[i] = individual.TranslatedObjectiveValues[i] / 10e-10
-			}
+func (normalization normalization) normalizeTranslatedObjectiveValues(translatedObjectiveValues []float64, normalizedObjectiveValues []float64) {
+	for i := range translatedObjectiveValues {
+		// objectives with the same value for the whole population are only translated
+		objectiveRange := normalization.nadirObjectivePoint[i] - normalization.idealObjectivePoint[i]
+		if objectiveRange <= minimumObjectiveRange {
+			objectiveRange = 1
 		}
+		normalizedObjectiveValues[i] = translatedObjectiveValues[i] / objectiveRange
 	}
 }
 
-func computeTranslatedExtremeObjectivePoint(numberOfObjectiveFunctions int, indexOfObjectiveFunction int, populationWithOverflow Population) []float64{
-	randomTranslatedObjectiveValue := populationWithOverflow[0].TranslatedObjectiveValues
-	var extremeObjectivePoint []float64
-	weightVector := initWeightVector(numberOfObjectiveFunctions, indexOfObjectiveFunction)
-	minimumASFValue := ASF(randomTranslatedObjectiveValue, weightVector)
-	extremeObjectivePoint = randomTranslatedObjectiveValue
+// normalizeObjectiveValues returns the normalized objective values of any point of objective space.
+func (normalization normalization) normalizeObjectiveValues(objectiveValues []float64) []float64 {
+	translatedObjectiveValues := make([]float64, len(objectiveValues))
+	for i, objectiveValue := range objectiveValues {
+		translatedObjectiveValues[i] = objectiveValue - normalization.idealObjectivePoint[i]
+	}
+	normalizedObjectiveValues := make([]float64, len(objectiveValues))
+	normalization.normalizeTranslatedObjectiveValues(translatedObjectiveValues, normalizedObjectiveValues)
+	return normalizedObjectiveValues
+}
 
-	for _, individual := range populationWithOverflow {
-		individualASFValue := ASF(individual.TranslatedObjectiveValues, weightVector)
-		if minimumASFValue > individualASFValue {
-			minimumASFValue = individualASFValue
-			extremeObjectivePoint = individual.TranslatedObjectiveValues
+// computeExtremeObjectivePointOf returns the objective point minimizing the achievement
+// scalarizing function of the objective axis, after translation by the ideal point.
+func computeExtremeObjectivePointOf(objectivePoints [][]float64, idealPoint []float64, indexOfObjectiveFunction int) []float64 {
+	weightVector := initWeightVector(len(idealPoint), indexOfObjectiveFunction)
+	translatedObjectivePoint := make([]float64, len(idealPoint))
+	var extremeObjectivePoint []float64
+	minimumASFValue := math.Inf(1)
+	for _, objectivePoint := range objectivePoints {
+		for i := range objectivePoint {
+			translatedObjectivePoint[i] = objectivePoint[i] - idealPoint[i]
+		}
+		if ASFValue := ASF(translatedObjectivePoint, weightVector); extremeObjectivePoint == nil || ASFValue < minimumASFValue {
+			minimumASFValue = ASFValue
+			extremeObjectivePoint = objectivePoint
 		}
 	}
 	return extremeObjectivePoint
 }
 
-func computeExtremeObjectivePoint(numberOfObjectiveFunctions int, indexOfObjectiveFunction int, populationWithOverflow Population) []float64{
-	randomObjectiveValue := populationWithOverflow[0].ObjectiveValues
-	var extremeObjectivePoint []float64
-	weightVector := initWeightVector(numberOfObjectiveFunctions, indexOfObjectiveFunction)
-	minimumASFValue := ASF(randomObjectiveValue, weightVector)
-	extremeObjectivePoint = randomObjectiveValue
+// computeNadirPointFromHyperplane returns the ideal point plus the intercepts of the
+// hyperplane through the extreme points with the objective axes, and whether the
+// hyperplane is not degenerate and every intercept is larger than minimumObjectiveRange.
+func computeNadirPointFromHyperplane(extremeObjectivePoints [][]float64, idealPoint []float64) ([]float64, bool) {
+	numberOfObjectiveFunctions := len(idealPoint)
+	extremeTranslatedObjectivePoints := make([][]float64, numberOfObjectiveFunctions)
+	B := make([]float64, numberOfObjectiveFunctions)
+	for i, extremeObjectivePoint := range extremeObjectivePoints {
+		extremeTranslatedObjectivePoints[i] = make([]float64, numberOfObjectiveFunctions)
+		for j := range extremeObjectivePoint {
+			extremeTranslatedObjectivePoints[i][j] = extremeObjectivePoint[j] - idealPoint[j]
+		}
+		B[i] = 1.0
+	}
 
-	for _, individual := range populationWithOverflow {
-		individualASFValue := ASF(individual.ObjectiveValues, weightVector)
-		if minimumASFValue > individualASFValue {
-			minimumASFValue = individualASFValue
-			extremeObjectivePoint = individual.ObjectiveValues
+	result, err := GaussPartial(extremeTranslatedObjectivePoints, B)
+	if err != nil {
+		return nil, false
+	}
+	nadirPoint := make([]float64, numberOfObjectiveFunctions)
+	for i, number := range result {
+		intercept := 1 / number
+		if math.IsNaN(intercept) || math.IsInf(intercept, 0) || intercept <= minimumObjectiveRange {
+			return nil, false
 		}
+		nadirPoint[i] = idealPoint[i] + intercept
 	}
-	return extremeObjectivePoint
+	return nadirPoint, true
+}
+
+func computeWorstObjectivePoint(population Population) []float64 {
+	worstObjectivePoint := append([]float64{}, population[0].ObjectiveValues...)
+	for _, individual := range population {
+		for i, objectiveValue := range individual.ObjectiveValues {
+			worstObjectivePoint[i] = math.Max(worstObjectivePoint[i], objectiveValue)
+		}
+	}
+	return worstObjectivePoint
 }
 
+// nonDominatedIndividualsOf returns the individuals of the population no other individual
+// dominates by objective values alone.
+func nonDominatedIndividualsOf(population Population) Population {
+	var nonDominatedIndividuals Population
+	for _, individual := range population {
+		isDominated := false
+		for _, anotherIndividual := range population {
+			if anotherIndividual.dominates(*individual) {
+				isDominated = true
+				break
+			}
+		}
+		if !isDominated {
+			nonDominatedIndividuals = append(nonDominatedIndividuals, individual)
+		}
+	}
+	return nonDominatedIndividuals
+}
 
 func translateObjectiveFunction(populationWithOverflow Population, indexOfObjectiveFunction int, idealValueOfObjectiveFunction float64) {
 	for _, individual := range populationWithOverflow {
@@ -116,36 +236,6 @@ func ASF(translatedObjectiveValues []float64, weight []float64) float64{
 	return max
 }
 
-func ComputeIntercepts(extremeTranslatedObjectivePoints [][]float64, numberOfObjectiveFunctions int)[]float64{
-	intercepts := make([]float64, numberOfObjectiveFunctions)
-
-	B := make([]float64, numberOfObjectiveFunctions)
-	for i := 0; i < numberOfObjectiveFunctions; i++{
-		B[i] = 1.0
-	}
-
-	result, error := GaussPartial(extremeTranslatedObjectivePoints, B)
-
-	isThereNegativeIntercept := false
-	for _, number := range result{
-		if number < 0{
-			isThereNegativeIntercept = true
-		}
-	}
-
-	if error == nil && !isThereNegativeIntercept {
-		for i, number := range result {
-			intercepts[i] = 1 / number
-		}
-		return intercepts
-	}else{
-		for i := 0; i < numberOfObjectiveFunctions; i++{
-			intercepts[i] = extremeTranslatedObjectivePoints[i][i]
-		}
-		return intercepts
-	}
-}
-
 //from open source project
 func GaussPartial(a0 [][]float64, b0 []float64) ([]float64, error) {
 	// make augmented matrix
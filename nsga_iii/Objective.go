@@ -0,0 +1,194 @@
+package nsga_iii
+
+import (
+	"fmt"
+	"sort"
+)
+
+type ObjectiveDirection int
+
+const (
+	Minimize ObjectiveDirection = iota
+	Maximize
+)
+
+// Objective is a single objective function optimized by the genetic algorithm.
+// Objectives with Maximize direction are negated when stored in ObjectiveValues,
+// so the rest of the algorithm always minimizes.
+type Objective interface {
+	Name() string
+	Direction() ObjectiveDirection
+	Evaluate(individual *Individual) float64
+}
+
+const (
+	SpreadObjectiveName               = "spread"
+	UniquenessObjectiveName           = "uniqueness"
+	PowerObjectiveName                = "power"
+	ResourcesUtilizationObjectiveName = "resources-utilization"
+	AssignmentDifferenceObjectiveName = "assignment-difference"
+	MemoryUtilizationObjectiveName    = "memory-utilization"
+	CPUUtilizationObjectiveName       = "cpu-utilization"
+)
+
+// DefaultObjectiveNames are the objectives optimized when GeneticAlgorithm.Objectives is empty.
+var DefaultObjectiveNames = []string{
+	SpreadObjectiveName,
+	UniquenessObjectiveName,
+	PowerObjectiveName,
+	ResourcesUtilizationObjectiveName,
+}
+
+type objectiveFunction struct {
+	name      string
+	direction ObjectiveDirection
+	evaluate  func(individual *Individual) float64
+}
+
+func (objective objectiveFunction) Name() string {
+	return objective.name
+}
+
+func (objective objectiveFunction) Direction() ObjectiveDirection {
+	return objective.direction
+}
+
+func (objective objectiveFunction) Evaluate(individual *Individual) float64 {
+	return objective.evaluate(individual)
+}
+
+// NewObjective wraps an evaluation function into an Objective.
+func NewObjective(name string, direction ObjectiveDirection, evaluate func(individual *Individual) float64) Objective {
+	return objectiveFunction{name: name, direction: direction, evaluate: evaluate}
+}
+
+func builtinObjectives() []Objective {
+	return []Objective{
+		NewObjective(SpreadObjectiveName, Minimize, func(individual *Individual) float64 {
+			return float64(individual.computeSpreadObjectiveFunction())
+		}),
+		NewObjective(UniquenessObjectiveName, Minimize, func(individual *Individual) float64 {
+			return float64(individual.computeUniquenessObjectiveFunction())
+		}),
+		NewObjective(PowerObjectiveName, Minimize, func(individual *Individual) float64 {
+			return individual.computePowerObjectiveFunction()
+		}),
+		NewObjective(ResourcesUtilizationObjectiveName, Minimize, func(individual *Individual) float64 {
+			return individual.computeResourcesUtilizationObjectiveFunction()
+		}),
+		NewObjective(AssignmentDifferenceObjectiveName, Minimize, func(individual *Individual) float64 {
+			return float64(individual.computeAssignmentDifferenceObjectiveFunction())
+		}),
+		NewObjective(MemoryUtilizationObjectiveName, Minimize, func(individual *Individual) float64 {
+			return individual.computeMemoryUtilizationObjectiveFunction()
+		}),
+		NewObjective(CPUUtilizationObjectiveName, Minimize, func(individual *Individual) float64 {
+			return individual.computeCPUUtilizationObjectiveFunction()
+		}),
+		NewObjective(NodeAffinityPreferenceObjectiveName, Minimize, func(individual *Individual) float64 {
+			return individual.computeNodeAffinityPreferenceObjectiveFunction()
+		}),
+		NewObjective(MigrationCountObjectiveName, Minimize, func(individual *Individual) float64 {
+			return float64(individual.computeMigrationCountObjectiveFunction())
+		}),
+		NewObjective(MigrationCostObjectiveName, Minimize, func(individual *Individual) float64 {
+			return individual.computeMigrationCostObjectiveFunction()
+		}),
+		TopologySpreadObjective{TopologyKey: TopologyKeyZone},
+		TopologySpreadObjective{TopologyKey: TopologyKeyRack},
+	}
+}
+
+// ObjectiveRegistry maps objective names to objectives so that a run can
+// select the objectives to optimize by name.
+type ObjectiveRegistry map[string]Objective
+
+// NewObjectiveRegistry returns a registry holding the built-in objectives.
+func NewObjectiveRegistry() ObjectiveRegistry {
+	registry := ObjectiveRegistry{}
+	for _, objective := range builtinObjectives() {
+		registry[objective.Name()] = objective
+	}
+	return registry
+}
+
+func (registry ObjectiveRegistry) Register(objective Objective) error {
+	if _, exists := registry[objective.Name()]; exists {
+		return fmt.Errorf("objective %q is already registered", objective.Name())
+	}
+	registry[objective.Name()] = objective
+	return nil
+}
+
+func (registry ObjectiveRegistry) Lookup(name string) (Objective, bool) {
+	objective, exists := registry[name]
+	return objective, exists
+}
+
+func (registry ObjectiveRegistry) Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Select returns the objectives with the given names in the given order.
+func (registry ObjectiveRegistry) Select(names ...string) ([]Objective, error) {
+	objectives := make([]Objective, 0, len(names))
+	selected := make(map[string]bool)
+	for _, name := range names {
+		objective, exists := registry[name]
+		if !exists {
+			return nil, fmt.Errorf("unknown objective %q", name)
+		}
+		if selected[name] {
+			return nil, fmt.Errorf("objective %q is selected more than once", name)
+		}
+		selected[name] = true
+		objectives = append(objectives, objective)
+	}
+	return objectives, nil
+}
+
+func defaultObjectives() []Objective {
+	objectives, _ := NewObjectiveRegistry().Select(DefaultObjectiveNames...)
+	return objectives
+}
+
+// RegisterObjective adds an objective to the registry of the genetic algorithm,
+// creating the registry with the built-in objectives if needed.
+func (g *GeneticAlgorithm) RegisterObjective(objective Objective) error {
+	if g.ObjectiveRegistry == nil {
+		g.ObjectiveRegistry = NewObjectiveRegistry()
+	}
+	return g.ObjectiveRegistry.Register(objective)
+}
+
+// SelectObjectives sets the objectives optimized by the genetic algorithm, in order.
+func (g *GeneticAlgorithm) SelectObjectives(names ...string) error {
+	if g.ObjectiveRegistry == nil {
+		g.ObjectiveRegistry = NewObjectiveRegistry()
+	}
+	objectives, err := g.ObjectiveRegistry.Select(names...)
+	if err != nil {
+		return err
+	}
+	if len(objectives) == 0 {
+		return fmt.Errorf("at least one objective must be selected")
+	}
+	g.Objectives = objectives
+	return nil
+}
+
+func (g GeneticAlgorithm) objectives() []Objective {
+	objectives := g.Objectives
+	if len(objectives) == 0 {
+		objectives = defaultObjectives()
+	}
+	if g.Rescheduling.Enabled {
+		objectives = g.reschedulingObjectives(objectives)
+	}
+	return objectives
+}
@@ -0,0 +1,46 @@
+package nsga_iii
+
+import (
+	"fmt"
+)
+
+// Optimizer is the many-objective algorithm evolving the population of a run. It selects
+// the parents and the survivors of every generation, while the genetic algorithm provides
+// the problem, the variation operators and the constraints shared by all optimizers.
+type Optimizer interface {
+	// Initialize validates the configuration of the optimizer and prepares it for a run
+	// starting from the given population.
+	Initialize(g GeneticAlgorithm, population Population) error
+	// NextPopulation returns the PopulationSize individuals of the next generation.
+	NextPopulation(g GeneticAlgorithm, generation int, parentPopulation Population) Population
+}
+
+// Run evolves a random feasible population with the optimizer for NumberOfGenerations
+// generations and returns the last population.
+func (g GeneticAlgorithm) Run(optimizer Optimizer) (Population, error) {
+	if g.PopulationSize < 2 {
+		return nil, fmt.Errorf("the population size must be at least 2, got %d", g.PopulationSize)
+	}
+	if len(g.AllNodes) == 0 || len(g.AllTasks) == 0 {
+		return nil, fmt.Errorf("there must be at least one node and one task, got %d nodes and %d tasks", len(g.AllNodes), len(g.AllTasks))
+	}
+
+	g = g.withRandomSource().withProblem().withMutationWeights()
+	if err := g.validateSeedingStrategies(); err != nil {
+		return nil, err
+	}
+	parentPopulation := g.GenerateRandomFeasiblePopulation()
+	if g.NormalizeConstraintViolations {
+		normalizeConstraintViolations(parentPopulation)
+	}
+	if err := optimizer.Initialize(g, parentPopulation); err != nil {
+		return nil, err
+	}
+
+	for t := 0; t < g.NumberOfGenerations; t++ {
+		parentPopulation = optimizer.NextPopulation(g, t, parentPopulation)
+		g.mutationWeights.adapt(parentPopulation)
+		parentPopulation = g.searchLocally(optimizer, t, parentPopulation)
+	}
+	return parentPopulation, nil
+}
@@ -0,0 +1,52 @@
+package nsga_iii
+
+import (
+	"math/rand"
+	"sync"
+)
+
+func (g GeneticAlgorithm) numberOfWorkers() int {
+	if g.Workers < 1 {
+		return 1
+	}
+	return g.Workers
+}
+
+// generateConcurrently creates numberOfIndividuals individuals by calling generate on
+// g.Workers goroutines. Every call gets a copy of the genetic algorithm whose random
+// source is seeded from the random source of the run, and the IDs are assigned in index
+// order, so the result does not depend on the number of workers or their scheduling.
+// Objectives and constraints must therefore be safe for concurrent use.
+func (g GeneticAlgorithm) generateConcurrently(numberOfIndividuals int, generate func(g GeneticAlgorithm, index int) *Individual) Population {
+	seeds := make([]int64, numberOfIndividuals)
+	ids := make([]string, numberOfIndividuals)
+	for i := 0; i < numberOfIndividuals; i++ {
+		seeds[i] = g.random().Int63()
+		ids[i] = g.nextIndividualID()
+	}
+
+	population := make(Population, numberOfIndividuals)
+	indexes := make(chan int)
+	var waitGroup sync.WaitGroup
+	for w := 0; w < g.numberOfWorkers(); w++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			workerGeneticAlgorithm := g
+			workerGeneticAlgorithm.Random = rand.New(rand.NewSource(0))
+			// the IDs generated by the worker are replaced by the IDs drawn in index order
+			workerGeneticAlgorithm.individualIDs = &idSequence{}
+			for i := range indexes {
+				workerGeneticAlgorithm.Random.Seed(seeds[i])
+				population[i] = generate(workerGeneticAlgorithm, i)
+				population[i].ID = ids[i]
+			}
+		}()
+	}
+	for i := 0; i < numberOfIndividuals; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	waitGroup.Wait()
+	return population
+}
@@ -0,0 +1,254 @@
+package nsga_iii
+
+import (
+	"math"
+	"math/rand"
+)
+
+// placement tracks the remaining resources and the tasks of every node while an
+// assignment is built task by task, so that operators can check hard constraints
+// before placing a task instead of relying on the constraint violation afterwards.
+type placement struct {
+	problem            *Problem
+	genome             []int32
+	remainingResources []float64
+	tasksOfNode        [][]int32
+}
+
+func newPlacement(problem *Problem) *placement {
+	genome := make([]int32, len(problem.Tasks))
+	for t := range genome {
+		genome[t] = Unassigned
+	}
+	remainingResources := make([]float64, len(problem.capacities))
+	copy(remainingResources, problem.capacities)
+	return &placement{problem: problem, genome: genome, remainingResources: remainingResources, tasksOfNode: make([][]int32, len(problem.Nodes))}
+}
+
+// placementOf returns the placement of a genome, with every assigned task placed on its
+// node whether or not it can be placed there.
+func placementOf(problem *Problem, genome []int32) *placement {
+	p := newPlacement(problem)
+	for t, nodeIndex := range genome {
+		if nodeIndex != Unassigned {
+			p.place(t, nodeIndex)
+		}
+	}
+	return p
+}
+
+// shuffledNodes returns the indexes of the nodes in random order.
+func (p *placement) shuffledNodes(random *rand.Rand) []int32 {
+	nodes := make([]int32, len(p.problem.Nodes))
+	for n := range nodes {
+		nodes[n] = int32(n)
+	}
+	shuffleNodes(nodes, random)
+	return nodes
+}
+
+func (p *placement) remainingResourcesOfNode(nodeIndex int32) []float64 {
+	numberOfResources := len(p.problem.ResourceNames)
+	return p.remainingResources[int(nodeIndex)*numberOfResources : (int(nodeIndex)+1)*numberOfResources]
+}
+
+// utilizationOfNode returns the largest share of a resource of the node used by its tasks.
+func (p *placement) utilizationOfNode(nodeIndex int32) float64 {
+	utilization := 0.0
+	capacities := p.problem.CapacitiesOfNode(int(nodeIndex))
+	for r, remainingResource := range p.remainingResourcesOfNode(nodeIndex) {
+		if capacities[r] > 0 {
+			utilization = math.Max(utilization, (capacities[r]-remainingResource)/capacities[r])
+		}
+	}
+	return utilization
+}
+
+// canPlace reports whether the task fits in the node without breaking its resources,
+// its required node affinity, its required task affinity and anti-affinity, or the
+// required task affinity and anti-affinity of the placed tasks selecting it.
+func (p *placement) canPlace(taskIndex int, nodeIndex int32) bool {
+	return p.problem.allowsNode(taskIndex, nodeIndex) &&
+		p.problem.fits(taskIndex, p.remainingResourcesOfNode(nodeIndex)) &&
+		p.satisfiesTaskAffinity(taskIndex, nodeIndex)
+}
+
+func (p *placement) place(taskIndex int, nodeIndex int32) {
+	remainingResources := p.remainingResourcesOfNode(nodeIndex)
+	for r, demand := range p.problem.DemandsOfTask(taskIndex) {
+		remainingResources[r] -= demand
+	}
+	p.tasksOfNode[nodeIndex] = append(p.tasksOfNode[nodeIndex], int32(taskIndex))
+	p.genome[taskIndex] = nodeIndex
+}
+
+// remove unassigns the task from its node, if it is placed on one.
+func (p *placement) remove(taskIndex int) {
+	nodeIndex := p.genome[taskIndex]
+	if nodeIndex == Unassigned {
+		return
+	}
+	remainingResources := p.remainingResourcesOfNode(nodeIndex)
+	for r, demand := range p.problem.DemandsOfTask(taskIndex) {
+		remainingResources[r] += demand
+	}
+	tasks := p.tasksOfNode[nodeIndex]
+	for i, anotherTaskIndex := range tasks {
+		if int(anotherTaskIndex) == taskIndex {
+			p.tasksOfNode[nodeIndex] = append(tasks[:i], tasks[i+1:]...)
+			break
+		}
+	}
+	p.genome[taskIndex] = Unassigned
+}
+
+// placeFirstFit places the task on the first of the nodes it can be placed on and
+// reports whether there was one.
+func (p *placement) placeFirstFit(taskIndex int, nodes []int32) bool {
+	for _, nodeIndex := range nodes {
+		if p.canPlace(taskIndex, nodeIndex) {
+			p.place(taskIndex, nodeIndex)
+			return true
+		}
+	}
+	return false
+}
+
+// placeBestFit places the task on the node, among the nodes it can be placed on, left with
+// the smallest sum of the shares of the given resources remaining, and reports whether
+// there was one. Ties go to the earlier node.
+func (p *placement) placeBestFit(taskIndex int, nodes []int32, resourceIndexes []int) bool {
+	return p.placeByRemainingShare(taskIndex, nodes, resourceIndexes, 1)
+}
+
+// placeWorstFit places the task on the node, among the nodes it can be placed on, left with
+// the largest sum of the shares of the given resources remaining, and reports whether
+// there was one. Ties go to the earlier node.
+func (p *placement) placeWorstFit(taskIndex int, nodes []int32, resourceIndexes []int) bool {
+	return p.placeByRemainingShare(taskIndex, nodes, resourceIndexes, -1)
+}
+
+// placeByRemainingShare places the task on the node minimizing the sum of the shares of the
+// given resources remaining after placing the task, multiplied by sign.
+func (p *placement) placeByRemainingShare(taskIndex int, nodes []int32, resourceIndexes []int, sign float64) bool {
+	bestNode := Unassigned
+	bestRemainingShare := math.Inf(1)
+	demands := p.problem.DemandsOfTask(taskIndex)
+	for _, nodeIndex := range nodes {
+		if !p.canPlace(taskIndex, nodeIndex) {
+			continue
+		}
+		capacities := p.problem.CapacitiesOfNode(int(nodeIndex))
+		remainingResources := p.remainingResourcesOfNode(nodeIndex)
+		remainingShare := 0.0
+		for _, r := range resourceIndexes {
+			if capacities[r] > 0 {
+				remainingShare += (remainingResources[r] - demands[r]) / capacities[r]
+			}
+		}
+		if sign*remainingShare < bestRemainingShare {
+			bestNode = nodeIndex
+			bestRemainingShare = sign * remainingShare
+		}
+	}
+	if bestNode == Unassigned {
+		return false
+	}
+	p.place(taskIndex, bestNode)
+	return true
+}
+
+// overloadOfNode returns how much every resource of the node is exceeded by its tasks,
+// zero for the resources that are not exceeded.
+func (p *placement) overloadOfNode(nodeIndex int32) []float64 {
+	remainingResources := p.remainingResourcesOfNode(nodeIndex)
+	overload := make([]float64, len(remainingResources))
+	for r, remainingResource := range remainingResources {
+		overload[r] = math.Max(-remainingResource, 0)
+	}
+	return overload
+}
+
+func (p *placement) satisfiesTaskAffinity(taskIndex int, nodeIndex int32) bool {
+	problem := p.problem
+	task := problem.Tasks[taskIndex]
+	for _, term := range task.TaskAntiAffinity {
+		domains := problem.topologyDomains(term.TopologyKey)
+		domain := domains.domainOfNode[nodeIndex]
+		if domain < 0 {
+			continue
+		}
+		for _, anotherNodeIndex := range domains.nodesOfDomain[domain] {
+			for _, anotherTaskIndex := range p.tasksOfNode[anotherNodeIndex] {
+				if int(anotherTaskIndex) != taskIndex && term.Selector.matches(problem.Tasks[anotherTaskIndex]) {
+					return false
+				}
+			}
+		}
+	}
+	for _, anotherTaskIndex := range problem.tasksWithTaskAntiAffinity {
+		anotherNodeIndex := p.genome[anotherTaskIndex]
+		if anotherNodeIndex == Unassigned || int(anotherTaskIndex) == taskIndex {
+			continue
+		}
+		for _, term := range problem.Tasks[anotherTaskIndex].TaskAntiAffinity {
+			if term.Selector.matches(task) && problem.topologyDomains(term.TopologyKey).share(nodeIndex, anotherNodeIndex) {
+				return false
+			}
+		}
+	}
+
+	for _, term := range task.TaskAffinity {
+		domains := problem.topologyDomains(term.TopologyKey)
+		isAnyMatchingTaskPlaced := false
+		isSatisfied := false
+		for anotherTaskIndex, anotherNodeIndex := range p.genome {
+			if anotherNodeIndex == Unassigned || anotherTaskIndex == taskIndex || !term.Selector.matches(problem.Tasks[anotherTaskIndex]) {
+				continue
+			}
+			isAnyMatchingTaskPlaced = true
+			if domains.share(nodeIndex, anotherNodeIndex) {
+				isSatisfied = true
+				break
+			}
+		}
+		if isAnyMatchingTaskPlaced && !isSatisfied {
+			return false
+		}
+	}
+	// a placed task selecting the task needs it in its domain unless another task it
+	// selects is already placed there
+	for _, anotherTaskIndex := range problem.tasksWithTaskAffinity {
+		anotherNodeIndex := p.genome[anotherTaskIndex]
+		if anotherNodeIndex == Unassigned || int(anotherTaskIndex) == taskIndex {
+			continue
+		}
+		for _, term := range problem.Tasks[anotherTaskIndex].TaskAffinity {
+			domains := problem.topologyDomains(term.TopologyKey)
+			if !term.Selector.matches(task) || domains.share(nodeIndex, anotherNodeIndex) {
+				continue
+			}
+			if !p.isAnyTaskPlacedInDomainOf(anotherNodeIndex, domains, term.Selector, int(anotherTaskIndex)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isAnyTaskPlacedInDomainOf reports whether a task matching the selector, other than
+// excludedTaskIndex, is placed in the domain of the node.
+func (p *placement) isAnyTaskPlacedInDomainOf(nodeIndex int32, domains *topologyDomains, selector TaskSelector, excludedTaskIndex int) bool {
+	domain := domains.domainOfNode[nodeIndex]
+	if domain < 0 {
+		return false
+	}
+	for _, anotherNodeIndex := range domains.nodesOfDomain[domain] {
+		for _, anotherTaskIndex := range p.tasksOfNode[anotherNodeIndex] {
+			if int(anotherTaskIndex) != excludedTaskIndex && selector.matches(p.problem.Tasks[anotherTaskIndex]) {
+				return true
+			}
+		}
+	}
+	return false
+}
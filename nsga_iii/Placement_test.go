@@ -0,0 +1,92 @@
+package nsga_iii
+
+import (
+	"testing"
+)
+
+func TestPlacementCanPlaceTaskAffinity(t *testing.T) {
+	nodes := []Node{
+		{ID: "n1", AvailableResources: Resources{CpuCores: 4, Memory: 4}},
+		{ID: "n2", AvailableResources: Resources{CpuCores: 4, Memory: 4}},
+	}
+	withB := []TaskAffinityTerm{{Selector: TaskSelector{TaskTypes: []string{"b"}}}}
+	tests := []struct {
+		name  string
+		tasks []Task
+		// placed holds the node of every task placed before the incoming task
+		placed       map[string]string
+		incomingTask string
+		// canPlace holds the expected result for every node by node ID
+		canPlace map[string]bool
+	}{
+		{
+			name: "affinity of the incoming task",
+			tasks: []Task{
+				{TaskID: "a", TaskType: "a", TaskAffinity: withB},
+				{TaskID: "b", TaskType: "b"},
+			},
+			placed:       map[string]string{"b": "n1"},
+			incomingTask: "a",
+			canPlace:     map[string]bool{"n1": true, "n2": false},
+		},
+		{
+			name: "affinity of a placed task selecting the incoming task",
+			tasks: []Task{
+				{TaskID: "a", TaskType: "a", TaskAffinity: withB},
+				{TaskID: "b", TaskType: "b"},
+			},
+			placed:       map[string]string{"a": "n1"},
+			incomingTask: "b",
+			canPlace:     map[string]bool{"n1": true, "n2": false},
+		},
+		{
+			name: "affinity of a placed task already satisfied by another task",
+			tasks: []Task{
+				{TaskID: "a", TaskType: "a", TaskAffinity: withB},
+				{TaskID: "b1", TaskType: "b"},
+				{TaskID: "b2", TaskType: "b"},
+			},
+			placed:       map[string]string{"a": "n1", "b1": "n1"},
+			incomingTask: "b2",
+			canPlace:     map[string]bool{"n1": true, "n2": true},
+		},
+		{
+			name: "anti-affinity of a placed task selecting the incoming task",
+			tasks: []Task{
+				{TaskID: "a", TaskType: "a", TaskAntiAffinity: withB},
+				{TaskID: "b", TaskType: "b"},
+			},
+			placed:       map[string]string{"a": "n1"},
+			incomingTask: "b",
+			canPlace:     map[string]bool{"n1": false, "n2": true},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			problem := NewProblem(nodes, test.tasks, nil)
+			incomingTaskIndex, _ := problem.TaskIndex(test.incomingTask)
+			for nodeID, expected := range test.canPlace {
+				nodeIndex, _ := problem.NodeIndex(nodeID)
+				p := newPlacement(problem)
+				for _, task := range test.tasks {
+					if placedNodeID, isPlaced := test.placed[task.TaskID]; isPlaced {
+						taskIndex, _ := problem.TaskIndex(task.TaskID)
+						placedNodeIndex, _ := problem.NodeIndex(placedNodeID)
+						p.place(int(taskIndex), placedNodeIndex)
+					}
+				}
+				if p.canPlace(int(incomingTaskIndex), nodeIndex) != expected {
+					t.Fatalf("canPlace(%s, %s) is %v, want %v", test.incomingTask, nodeID, !expected, expected)
+				}
+				if !expected {
+					continue
+				}
+				p.place(int(incomingTaskIndex), nodeIndex)
+				violation := TaskAffinityConstraint{}.Violation(problem.NewIndividual("", p.genome))
+				if violation != 0 {
+					t.Fatalf("placing %s on %s violates the task affinity by %v", test.incomingTask, nodeID, violation)
+				}
+			}
+		})
+	}
+}
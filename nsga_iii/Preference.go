@@ -0,0 +1,111 @@
+package nsga_iii
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+const defaultPreferenceRadius = 0.2
+
+// PreferencePoint is a region of interest of objective space, given either as an aspiration
+// point or as a weight vector, with one component per objective of the problem.
+type PreferencePoint struct {
+	// AspirationPoint holds the desired value of every objective, as returned by the
+	// objective and not negated for maximized objectives.
+	AspirationPoint []float64
+	// Weights holds the importance of every objective. The region of interest is where the
+	// weighted normalized objective values are equal, so that the objectives with larger
+	// weights are kept closer to their ideal value.
+	Weights []float64
+}
+
+func (preferencePoint PreferencePoint) validate(numberOfObjectiveFunctions int) error {
+	if (len(preferencePoint.AspirationPoint) == 0) == (len(preferencePoint.Weights) == 0) {
+		return fmt.Errorf("a preference point needs either an aspiration point or weights")
+	}
+	if len(preferencePoint.AspirationPoint) != 0 && len(preferencePoint.AspirationPoint) != numberOfObjectiveFunctions {
+		return fmt.Errorf("aspiration points must have %d components, got %d", numberOfObjectiveFunctions, len(preferencePoint.AspirationPoint))
+	}
+	if len(preferencePoint.Weights) != 0 {
+		if len(preferencePoint.Weights) != numberOfObjectiveFunctions {
+			return fmt.Errorf("weights must have %d components, got %d", numberOfObjectiveFunctions, len(preferencePoint.Weights))
+		}
+		for _, weight := range preferencePoint.Weights {
+			if weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+				return fmt.Errorf("weights must be finite and non-negative, got %v", preferencePoint.Weights)
+			}
+		}
+	}
+	return nil
+}
+
+// center returns the point of the unit simplex in normalized objective space the
+// preference point refers to.
+func (preferencePoint PreferencePoint) center(objectives []Objective, normalization normalization) []float64 {
+	center := make([]float64, len(normalization.idealObjectivePoint))
+	if len(preferencePoint.Weights) != 0 {
+		for k, weight := range preferencePoint.Weights {
+			center[k] = 1 / math.Max(weight, math.Pow(10, -6))
+		}
+	} else {
+		aspirationPoint := make([]float64, len(preferencePoint.AspirationPoint))
+		for k, aspirationValue := range preferencePoint.AspirationPoint {
+			aspirationPoint[k] = aspirationValue
+			if objectives[k].Direction() == Maximize {
+				aspirationPoint[k] = -aspirationValue
+			}
+		}
+		// aspiration values better than the ideal value lie on the boundary of the simplex
+		for k, normalizedAspirationValue := range normalization.normalizeObjectiveValues(aspirationPoint) {
+			center[k] = math.Max(normalizedAspirationValue, 0)
+		}
+	}
+	return projectOntoSimplex(center)
+}
+
+// projectOntoSimplex scales non-negative coordinates to sum up to one, and returns the
+// center of the simplex for coordinates summing up to zero or not finite.
+func projectOntoSimplex(coordinates []float64) []float64 {
+	sum := 0.0
+	for _, coordinate := range coordinates {
+		sum += coordinate
+	}
+	for k := range coordinates {
+		if sum > 0 && !math.IsInf(sum, 0) && !math.IsNaN(sum) {
+			coordinates[k] /= sum
+		} else {
+			coordinates[k] = 1.0 / float64(len(coordinates))
+		}
+	}
+	return coordinates
+}
+
+// generatePreferenceReferencePoints places a copy of the reference points of the NSGA-III
+// layers, shrunk by PreferenceRadius, around every preference point, as in R-NSGA-III.
+// Aspiration points are normalized with the normalization of the population.
+func (nsga3 NSGA3) generatePreferenceReferencePoints(population Population) []*ReferencePoint {
+	numberOfObjectiveFunctions := len(population[0].ObjectiveValues)
+	radius := nsga3.PreferenceRadius
+	if radius <= 0 {
+		radius = defaultPreferenceRadius
+	}
+	normalization := nsga3.runNormalizer().computeNormalization(population)
+	neighborhood := nsga3.generateReferencePoints(numberOfObjectiveFunctions)
+
+	var referencePoints []*ReferencePoint
+	for p, preferencePoint := range nsga3.PreferencePoints {
+		center := preferencePoint.center(population[0].Problem.Objectives, normalization)
+		for i, neighbor := range neighborhood {
+			coordinates := make([]float64, numberOfObjectiveFunctions)
+			for k := range coordinates {
+				coordinates[k] = math.Max(center[k]+radius*(neighbor.Coordinates[k]-1.0/float64(numberOfObjectiveFunctions)), 0)
+			}
+			referencePoints = append(referencePoints, &ReferencePoint{
+				ID:          "preference-point-" + strconv.Itoa(p) + "-reference-point-" + strconv.Itoa(i),
+				Coordinates: projectOntoSimplex(coordinates),
+			})
+		}
+	}
+	return referencePoints
+}
@@ -0,0 +1,200 @@
+package nsga_iii
+
+import (
+	"sync"
+)
+
+// Unassigned is the gene of a task that is not placed on any node.
+const Unassigned int32 = -1
+
+// Problem is the immutable scheduling instance shared by all the individuals of a run.
+// Individuals refer to nodes, tasks and resource dimensions by their index in Nodes,
+// Tasks and ResourceNames; string IDs are only used at the API boundary.
+type Problem struct {
+	Nodes []Node
+	Tasks []Task
+	// ResourceNames are CpuCores, Memory and every extended dimension required by the tasks.
+	ResourceNames []string
+	// OriginalAssignment holds the node index of every task in the original assignment.
+	OriginalAssignment []int32
+	Objectives         []Objective
+	Constraints        []Constraint
+
+	hasOriginalAssignment bool
+	nodeIndexByID         map[string]int32
+	taskIndexByID         map[string]int32
+	resourceIndexByName   map[string]int
+	// capacities and demands are stored node-major and task-major, one value per resource dimension
+	capacities []float64
+	demands    []float64
+
+	taskTypeIndexByTaskType map[string]int32
+	taskTypeIndexes         []int32
+	// allowedNodes is nil for the tasks without required node affinity
+	allowedNodes                    [][]bool
+	allowedNodeIndexes              [][]int32
+	tasksWithPreferredNodeAffinity  []int32
+	tasksWithTaskAffinity           []int32
+	tasksWithTaskAntiAffinity       []int32
+	topologyDomainsByTopologyKey    map[string]*topologyDomains
+	topologyDomainsByTopologyKeyMux sync.Mutex
+}
+
+// NewProblem indexes the nodes, the tasks and the original assignment of a run, whose
+// individuals are evaluated against the default objectives and constraints.
+func NewProblem(nodes []Node, tasks []Task, nodeIdOfTaskIdOriginalAssignment map[string]string) *Problem {
+	problem := &Problem{
+		Nodes:                        nodes,
+		Tasks:                        tasks,
+		ResourceNames:                resourceNamesOfTasks(tasks),
+		OriginalAssignment:           make([]int32, len(tasks)),
+		Objectives:                   defaultObjectives(),
+		Constraints:                  DefaultConstraints(),
+		hasOriginalAssignment:        len(nodeIdOfTaskIdOriginalAssignment) != 0,
+		nodeIndexByID:                make(map[string]int32),
+		taskIndexByID:                make(map[string]int32),
+		resourceIndexByName:          make(map[string]int),
+		taskTypeIndexByTaskType:      make(map[string]int32),
+		taskTypeIndexes:              make([]int32, len(tasks)),
+		allowedNodes:                 make([][]bool, len(tasks)),
+		allowedNodeIndexes:           make([][]int32, len(tasks)),
+		topologyDomainsByTopologyKey: make(map[string]*topologyDomains),
+	}
+	for r, name := range problem.ResourceNames {
+		problem.resourceIndexByName[name] = r
+	}
+
+	allNodeIndexes := make([]int32, len(nodes))
+	problem.capacities = make([]float64, len(nodes)*len(problem.ResourceNames))
+	for n, node := range nodes {
+		problem.nodeIndexByID[node.ID] = int32(n)
+		allNodeIndexes[n] = int32(n)
+		for r, name := range problem.ResourceNames {
+			problem.capacities[n*len(problem.ResourceNames)+r] = node.AvailableResources.Get(name)
+		}
+	}
+
+	problem.demands = make([]float64, len(tasks)*len(problem.ResourceNames))
+	for t, task := range tasks {
+		problem.taskIndexByID[task.TaskID] = int32(t)
+		for r, name := range problem.ResourceNames {
+			problem.demands[t*len(problem.ResourceNames)+r] = task.RequiredResources.Get(name)
+		}
+
+		if _, exists := problem.taskTypeIndexByTaskType[task.TaskType]; !exists {
+			problem.taskTypeIndexByTaskType[task.TaskType] = int32(len(problem.taskTypeIndexByTaskType))
+		}
+		problem.taskTypeIndexes[t] = problem.taskTypeIndexByTaskType[task.TaskType]
+
+		problem.allowedNodeIndexes[t] = allNodeIndexes
+		if len(task.NodeAffinity.Required) != 0 {
+			problem.allowedNodes[t] = make([]bool, len(nodes))
+			var allowedNodeIndexes []int32
+			for n, node := range nodes {
+				if task.allowsNode(node) {
+					problem.allowedNodes[t][n] = true
+					allowedNodeIndexes = append(allowedNodeIndexes, int32(n))
+				}
+			}
+			if len(allowedNodeIndexes) != 0 {
+				problem.allowedNodeIndexes[t] = allowedNodeIndexes
+			}
+		}
+		if len(task.NodeAffinity.Preferred) != 0 {
+			problem.tasksWithPreferredNodeAffinity = append(problem.tasksWithPreferredNodeAffinity, int32(t))
+		}
+		if len(task.TaskAffinity) != 0 {
+			problem.tasksWithTaskAffinity = append(problem.tasksWithTaskAffinity, int32(t))
+		}
+		if len(task.TaskAntiAffinity) != 0 {
+			problem.tasksWithTaskAntiAffinity = append(problem.tasksWithTaskAntiAffinity, int32(t))
+		}
+	}
+
+	for t, task := range tasks {
+		problem.OriginalAssignment[t] = Unassigned
+		if nodeIndex, exists := problem.nodeIndexByID[nodeIdOfTaskIdOriginalAssignment[task.TaskID]]; exists {
+			problem.OriginalAssignment[t] = nodeIndex
+		}
+	}
+	return problem
+}
+
+func (problem *Problem) NodeIndex(nodeID string) (int32, bool) {
+	nodeIndex, exists := problem.nodeIndexByID[nodeID]
+	return nodeIndex, exists
+}
+
+func (problem *Problem) TaskIndex(taskID string) (int32, bool) {
+	taskIndex, exists := problem.taskIndexByID[taskID]
+	return taskIndex, exists
+}
+
+func (problem *Problem) ResourceIndex(name string) (int, bool) {
+	resourceIndex, exists := problem.resourceIndexByName[name]
+	return resourceIndex, exists
+}
+
+// CapacitiesOfNode returns the available resources of the node indexed by ResourceNames.
+// The returned slice must not be modified.
+func (problem *Problem) CapacitiesOfNode(nodeIndex int) []float64 {
+	numberOfResources := len(problem.ResourceNames)
+	return problem.capacities[nodeIndex*numberOfResources : (nodeIndex+1)*numberOfResources]
+}
+
+// DemandsOfTask returns the required resources of the task indexed by ResourceNames.
+// The returned slice must not be modified.
+func (problem *Problem) DemandsOfTask(taskIndex int) []float64 {
+	numberOfResources := len(problem.ResourceNames)
+	return problem.demands[taskIndex*numberOfResources : (taskIndex+1)*numberOfResources]
+}
+
+// allowsNode reports whether the required node affinity of the task is satisfied by the node.
+func (problem *Problem) allowsNode(taskIndex int, nodeIndex int32) bool {
+	return problem.allowedNodes[taskIndex] == nil || problem.allowedNodes[taskIndex][nodeIndex]
+}
+
+// fits reports whether the task fits in the remaining resources of a node.
+func (problem *Problem) fits(taskIndex int, remainingResourcesOfNode []float64) bool {
+	for r, demand := range problem.DemandsOfTask(taskIndex) {
+		if demand > 0 && demand > remainingResourcesOfNode[r] {
+			return false
+		}
+	}
+	return true
+}
+
+// GenomeOf encodes an assignment of task IDs to node IDs. Tasks missing from the
+// assignment or assigned to unknown nodes are Unassigned.
+func (problem *Problem) GenomeOf(nodeIdOfTaskIdAssignment map[string]string) []int32 {
+	genome := make([]int32, len(problem.Tasks))
+	for t, task := range problem.Tasks {
+		genome[t] = Unassigned
+		if nodeIndex, exists := problem.nodeIndexByID[nodeIdOfTaskIdAssignment[task.TaskID]]; exists {
+			genome[t] = nodeIndex
+		}
+	}
+	return genome
+}
+
+// AssignmentOf decodes a genome into an assignment of task IDs to node IDs, in which
+// unassigned tasks are assigned to the empty node ID.
+func (problem *Problem) AssignmentOf(genome []int32) map[string]string {
+	nodeIdOfTaskIdAssignment := make(map[string]string, len(genome))
+	for t, nodeIndex := range genome {
+		nodeIdOfTaskIdAssignment[problem.Tasks[t].TaskID] = ""
+		if nodeIndex != Unassigned {
+			nodeIdOfTaskIdAssignment[problem.Tasks[t].TaskID] = problem.Nodes[nodeIndex].ID
+		}
+	}
+	return nodeIdOfTaskIdAssignment
+}
+
+// NewIndividual evaluates the individual with the given genome.
+func (problem *Problem) NewIndividual(id string, genome []int32) *Individual {
+	newIndividual := Individual{ID: id, Problem: problem, Genome: genome}
+	newIndividual.ComputeValues()
+	newIndividual.TranslatedObjectiveValues = make([]float64, len(newIndividual.ObjectiveValues))
+	newIndividual.NormalizedObjectiveValues = make([]float64, len(newIndividual.ObjectiveValues))
+	return &newIndividual
+}
@@ -0,0 +1,63 @@
+package nsga_iii
+
+import (
+	"math/rand"
+	"strconv"
+	"sync/atomic"
+)
+
+// globalSource draws from the global source of math/rand, which is safe for concurrent use.
+type globalSource struct{}
+
+func (source globalSource) Int63() int64 {
+	return rand.Int63()
+}
+
+func (source globalSource) Uint64() uint64 {
+	return rand.Uint64()
+}
+
+func (source globalSource) Seed(seed int64) {}
+
+var globalRandom = rand.New(globalSource{})
+
+// idSequence generates the IDs of the individuals of a run in creation order.
+type idSequence struct {
+	prefix string
+	next   uint64
+}
+
+func (sequence *idSequence) nextID() string {
+	return sequence.prefix + strconv.FormatUint(atomic.AddUint64(&sequence.next, 1), 10)
+}
+
+var globalIndividualIDs = &idSequence{prefix: "individual-"}
+
+// random returns the random source of the run. The global source of math/rand is
+// used when neither Random nor Seed were given.
+func (g GeneticAlgorithm) random() *rand.Rand {
+	if g.Random != nil {
+		return g.Random
+	}
+	return globalRandom
+}
+
+// withRandomSource returns a copy of the genetic algorithm whose random source is
+// derived from Seed and whose individual IDs are numbered from the start of the run
+// when it is seeded, so that runs with the same input and seed produce the same individuals.
+func (g GeneticAlgorithm) withRandomSource() GeneticAlgorithm {
+	if g.Random == nil && g.Seed != 0 {
+		g.Random = rand.New(rand.NewSource(g.Seed))
+	}
+	if g.Random != nil && g.individualIDs == nil {
+		g.individualIDs = &idSequence{prefix: "individual-"}
+	}
+	return g
+}
+
+func (g GeneticAlgorithm) nextIndividualID() string {
+	if g.individualIDs == nil {
+		return globalIndividualIDs.nextID()
+	}
+	return g.individualIDs.nextID()
+}
@@ -0,0 +1,100 @@
+package nsga_iii
+
+import (
+	"math"
+	"sort"
+)
+
+// Repair restores the feasibility of an infeasible individual by changing its genome, and
+// recomputes its values.
+type Repair interface {
+	Repair(individual *Individual)
+}
+
+// BestFitRepair unassigns the tasks breaking their required node affinity and, from every
+// overloaded node, the fewest tasks it can so that no resource of the node is exceeded,
+// preferring the tasks covering most of the overload and then the smallest ones, the size
+// of a task being the sum of its demands relative to the largest capacities. The unassigned tasks are then
+// placed again, largest first, on the best fitting node they can be placed on, or left
+// unassigned. Violations of the other constraints are left to constraint domination.
+type BestFitRepair struct{}
+
+func (repair BestFitRepair) Repair(individual *Individual) {
+	problem := individual.Problem
+	placement := placementOf(problem, individual.Genome)
+	demandShares := make([]float64, len(problem.Tasks))
+	largestCapacities := computeLargestCapacities(problem)
+	for t := range problem.Tasks {
+		for r, demand := range problem.DemandsOfTask(t) {
+			if largestCapacities[r] > 0 {
+				demandShares[t] += demand / largestCapacities[r]
+			}
+		}
+	}
+
+	var removedTasks []int
+	for t, nodeIndex := range placement.genome {
+		if nodeIndex != Unassigned && !problem.allowsNode(t, nodeIndex) {
+			placement.remove(t)
+			removedTasks = append(removedTasks, t)
+		}
+	}
+	for n := range problem.Nodes {
+		for {
+			overload := placement.overloadOfNode(int32(n))
+			taskToRemove := -1
+			largestCoverage := 0.0
+			for _, task := range placement.tasksOfNode[n] {
+				coverage := coverageOfOverload(problem.DemandsOfTask(int(task)), overload)
+				if coverage > largestCoverage || (coverage > 0 && coverage == largestCoverage && demandShares[task] < demandShares[taskToRemove]) {
+					taskToRemove = int(task)
+					largestCoverage = coverage
+				}
+			}
+			if taskToRemove < 0 {
+				break
+			}
+			placement.remove(taskToRemove)
+			removedTasks = append(removedTasks, taskToRemove)
+		}
+	}
+	if len(removedTasks) == 0 {
+		return
+	}
+
+	sort.SliceStable(removedTasks, func(i, j int) bool {
+		return demandShares[removedTasks[i]] > demandShares[removedTasks[j]]
+	})
+	for _, task := range removedTasks {
+		placement.placeBestFit(task, problem.allowedNodeIndexes[task], allResourceIndexes(problem))
+	}
+	copy(individual.Genome, placement.genome)
+	individual.ComputeValues()
+}
+
+// coverageOfOverload returns the sum over the exceeded resources of the share of the
+// overload the demands would free.
+func coverageOfOverload(demands []float64, overload []float64) float64 {
+	coverage := 0.0
+	for r, overloadOfResource := range overload {
+		if overloadOfResource > 0 && demands[r] > 0 {
+			if demands[r] >= overloadOfResource {
+				coverage += 1
+			} else {
+				coverage += demands[r] / overloadOfResource
+			}
+		}
+	}
+	return coverage
+}
+
+// computeLargestCapacities returns the largest capacity of every resource over the nodes.
+func computeLargestCapacities(problem *Problem) []float64 {
+	largestCapacities := make([]float64, len(problem.ResourceNames))
+	for n := range problem.Nodes {
+		for r, capacity := range problem.CapacitiesOfNode(n) {
+			largestCapacities[r] = math.Max(largestCapacities[r], capacity)
+		}
+	}
+	return largestCapacities
+}
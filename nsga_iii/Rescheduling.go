@@ -0,0 +1,137 @@
+package nsga_iii
+
+import (
+	"math"
+	"strconv"
+)
+
+const (
+	MigrationCountObjectiveName  = "migration-count"
+	MigrationCostObjectiveName   = "migration-cost"
+	MigrationLimitConstraintName = "migration-limit"
+)
+
+const defaultMaximumSeedMoves = 3
+
+// ReschedulingOptions turn a run into an incremental rescheduling of the tasks placed
+// according to GeneticAlgorithm.NodeIdOfTaskIdOriginalAssignment.
+type ReschedulingOptions struct {
+	Enabled bool
+	// SeedRatio is the fraction of the initial population derived from the original
+	// assignment. At least one individual keeps the original assignment unchanged.
+	SeedRatio float64
+	// MaximumSeedMoves bounds the number of tasks moved away from the original
+	// assignment in every seeded individual but the first one.
+	MaximumSeedMoves int
+	// MigrationCostWeighted optimizes the memory of the migrated tasks instead of their number.
+	MigrationCostWeighted bool
+	// MaxMigrations is a hard cap on the number of migrated tasks when positive.
+	MaxMigrations int
+}
+
+// isMigrated reports whether the task was placed on a node in the original assignment
+// and is placed on another node, or on none, by the individual.
+func (individual *Individual) isMigrated(taskIndex int) bool {
+	originalNodeIndex := individual.Problem.OriginalAssignment[taskIndex]
+	return originalNodeIndex != Unassigned && individual.Genome[taskIndex] != originalNodeIndex
+}
+
+func (individual *Individual) computeMigrationCountObjectiveFunction() int {
+	numberOfMigrations := 0
+	for t := range individual.Genome {
+		if individual.isMigrated(t) {
+			numberOfMigrations++
+		}
+	}
+	return numberOfMigrations
+}
+
+func (individual *Individual) computeMigrationCostObjectiveFunction() float64 {
+	migrationCost := 0.0
+	for t, task := range individual.Problem.Tasks {
+		if individual.isMigrated(t) {
+			migrationCost += task.RequiredResources.Memory
+		}
+	}
+	return migrationCost
+}
+
+// MigrationLimitConstraint is violated by every migrated task beyond MaxMigrations.
+type MigrationLimitConstraint struct {
+	MaxMigrations int
+}
+
+func (constraint MigrationLimitConstraint) Name() string {
+	return MigrationLimitConstraintName + ":" + strconv.Itoa(constraint.MaxMigrations)
+}
+
+func (constraint MigrationLimitConstraint) Violation(individual *Individual) float64 {
+	return math.Max(0, float64(individual.computeMigrationCountObjectiveFunction()-constraint.MaxMigrations))
+}
+
+// reschedulingObjectives appends the migration objective to the objectives unless it is already optimized.
+func (g GeneticAlgorithm) reschedulingObjectives(objectives []Objective) []Objective {
+	migrationObjectiveName := MigrationCountObjectiveName
+	if g.Rescheduling.MigrationCostWeighted {
+		migrationObjectiveName = MigrationCostObjectiveName
+	}
+	for _, objective := range objectives {
+		if objective.Name() == migrationObjectiveName {
+			return objectives
+		}
+	}
+	migrationObjective, _ := NewObjectiveRegistry().Lookup(migrationObjectiveName)
+	return append(append([]Objective{}, objectives...), migrationObjective)
+}
+
+func (g GeneticAlgorithm) reschedulingConstraints(constraints []Constraint) []Constraint {
+	if g.Rescheduling.MaxMigrations <= 0 {
+		return constraints
+	}
+	return append(append([]Constraint{}, constraints...), MigrationLimitConstraint{MaxMigrations: g.Rescheduling.MaxMigrations})
+}
+
+func (g GeneticAlgorithm) numberOfReschedulingSeeds() int {
+	if !g.Rescheduling.Enabled || len(g.NodeIdOfTaskIdOriginalAssignment) == 0 {
+		return 0
+	}
+	numberOfSeeds := int(g.Rescheduling.SeedRatio * float64(g.PopulationSize))
+	if numberOfSeeds < 1 {
+		numberOfSeeds = 1
+	}
+	if numberOfSeeds > g.PopulationSize {
+		numberOfSeeds = g.PopulationSize
+	}
+	return numberOfSeeds
+}
+
+// generateIndividualFromOriginalAssignment keeps the original assignment, places the tasks
+// missing from it first-fit and then moves up to numberOfMoves random tasks to other nodes
+// they can be placed on.
+func (g GeneticAlgorithm) generateIndividualFromOriginalAssignment(numberOfMoves int) *Individual {
+	placement := newPlacement(g.problem)
+	var tasksToPlace []int
+	for t, nodeIndex := range g.problem.OriginalAssignment {
+		if nodeIndex != Unassigned {
+			placement.place(t, nodeIndex)
+		} else {
+			tasksToPlace = append(tasksToPlace, t)
+		}
+	}
+
+	nodes := placement.shuffledNodes(g.random())
+	for _, t := range tasksToPlace {
+		placement.placeFirstFit(t, nodes)
+	}
+
+	for i := 0; i < numberOfMoves && len(g.problem.Tasks) > 0; i++ {
+		t := g.random().Intn(len(g.problem.Tasks))
+		originalNodeIndex := placement.genome[t]
+		placement.remove(t)
+		if !placement.placeFirstFit(t, placement.shuffledNodes(g.random())) && originalNodeIndex != Unassigned {
+			placement.place(t, originalNodeIndex)
+		}
+	}
+
+	return g.newIndividual(placement.genome)
+}
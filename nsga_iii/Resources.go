@@ -0,0 +1,67 @@
+package nsga_iii
+
+import (
+	"sort"
+)
+
+// Well known resource dimensions. CpuCores and Memory are stored in their own
+// fields of Resources, any other dimension is stored in Resources.Extended.
+const (
+	ResourceCpuCores         = "cpu"
+	ResourceMemory           = "memory"
+	ResourceEphemeralStorage = "ephemeral-storage"
+	ResourceNetworkBandwidth = "network-bandwidth"
+	ResourcePIDs             = "pids"
+)
+
+func (resources Resources) Get(name string) float64 {
+	switch name {
+	case ResourceCpuCores:
+		return resources.CpuCores
+	case ResourceMemory:
+		return resources.Memory
+	default:
+		return resources.Extended[name]
+	}
+}
+
+func (resources *Resources) Set(name string, value float64) {
+	switch name {
+	case ResourceCpuCores:
+		resources.CpuCores = value
+	case ResourceMemory:
+		resources.Memory = value
+	default:
+		if resources.Extended == nil {
+			resources.Extended = make(map[string]float64)
+		}
+		resources.Extended[name] = value
+	}
+}
+
+// Names returns the resource dimensions of resources: CpuCores and Memory first,
+// followed by the extended dimensions in lexical order.
+func (resources Resources) Names() []string {
+	names := []string{ResourceCpuCores, ResourceMemory}
+	return append(names, sortedExtendedResourceNames(resources.Extended)...)
+}
+
+func sortedExtendedResourceNames(extended map[string]float64) []string {
+	var names []string
+	for name := range extended {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resourceNamesOfTasks returns CpuCores, Memory and every extended dimension required by the tasks.
+func resourceNamesOfTasks(tasks []Task) []string {
+	extended := make(map[string]float64)
+	for _, task := range tasks {
+		for name := range task.RequiredResources.Extended {
+			extended[name] = 0
+		}
+	}
+	return Resources{Extended: extended}.Names()
+}
@@ -0,0 +1,166 @@
+package nsga_iii
+
+import (
+	"math"
+	"sort"
+)
+
+// SPEA2 keeps an archive of PopulationSize individuals selected by their strength Pareto
+// fitness, the strengths of the individuals dominating them plus a density term decreasing
+// with the distance to their k-th nearest neighbor. Archives with too many non-dominated
+// individuals are truncated by removing the most crowded individual one at a time.
+type SPEA2 struct {
+	archiveFitness []float64
+}
+
+func (spea2 *SPEA2) Initialize(g GeneticAlgorithm, population Population) error {
+	spea2.archiveFitness, _ = spea2.computeFitness(population)
+	return nil
+}
+
+func (spea2 *SPEA2) NextPopulation(g GeneticAlgorithm, generation int, archive Population) Population {
+	newPopulation := g.makeNewPopulationBySelection(archive, spea2.binaryTournamentSelection(g))
+	unionOfArchiveAndNewPopulation := g.combinePopulation(archive, newPopulation)
+	if g.NormalizeConstraintViolations {
+		normalizeConstraintViolations(unionOfArchiveAndNewPopulation)
+	}
+
+	fitness, distances := spea2.computeFitness(unionOfArchiveAndNewPopulation)
+	selectedIndexes := spea2.selectEnvironmentally(fitness, distances, g.PopulationSize)
+	nextArchive := make(Population, len(selectedIndexes))
+	spea2.archiveFitness = make([]float64, len(selectedIndexes))
+	for i, index := range selectedIndexes {
+		nextArchive[i] = unionOfArchiveAndNewPopulation[index]
+		spea2.archiveFitness[i] = fitness[index]
+	}
+	return nextArchive
+}
+
+// binaryTournamentSelection returns the selection of the individual with the lower fitness
+// out of two random archive members.
+func (spea2 *SPEA2) binaryTournamentSelection(g GeneticAlgorithm) func(archive Population) Individual {
+	return func(archive Population) Individual {
+		firstIndex := g.random().Intn(len(archive))
+		secondIndex := g.random().Intn(len(archive))
+		if spea2.archiveFitness[secondIndex] < spea2.archiveFitness[firstIndex] {
+			return *archive[secondIndex]
+		}
+		return *archive[firstIndex]
+	}
+}
+
+// computeFitness returns the fitness of every individual, below one for the non-dominated
+// ones, and the distances between the individuals in normalized objective space.
+func (spea2 *SPEA2) computeFitness(population Population) ([]float64, [][]float64) {
+	strengths := make([]int, len(population))
+	dominators := make([][]int, len(population))
+	for i, individual := range population {
+		for j, anotherIndividual := range population {
+			if i != j && individual.constraintDominate(*anotherIndividual) {
+				strengths[i]++
+				dominators[j] = append(dominators[j], i)
+			}
+		}
+	}
+
+	distances := normalizedObjectiveDistances(population)
+	k := int(math.Sqrt(float64(len(population))))
+	fitness := make([]float64, len(population))
+	for i := range population {
+		for _, dominator := range dominators[i] {
+			fitness[i] += float64(strengths[dominator])
+		}
+		distancesToOthers := make([]float64, 0, len(population)-1)
+		for j, distance := range distances[i] {
+			if j != i {
+				distancesToOthers = append(distancesToOthers, distance)
+			}
+		}
+		sort.Float64s(distancesToOthers)
+		kthNearestDistance := 0.0
+		if len(distancesToOthers) > 0 {
+			kthNearestDistance = distancesToOthers[int(math.Min(float64(k), float64(len(distancesToOthers))))-1]
+		}
+		fitness[i] += 1 / (kthNearestDistance + 2)
+	}
+	return fitness, distances
+}
+
+// selectEnvironmentally returns the indexes of the archive members: the non-dominated
+// individuals, completed with the fittest dominated ones or truncated to archiveSize.
+func (spea2 *SPEA2) selectEnvironmentally(fitness []float64, distances [][]float64, archiveSize int) []int {
+	var selectedIndexes []int
+	for i := range fitness {
+		if fitness[i] < 1 {
+			selectedIndexes = append(selectedIndexes, i)
+		}
+	}
+
+	if len(selectedIndexes) < archiveSize {
+		indexes := make([]int, len(fitness))
+		for i := range indexes {
+			indexes[i] = i
+		}
+		sort.SliceStable(indexes, func(a, b int) bool {
+			return fitness[indexes[a]] < fitness[indexes[b]]
+		})
+		return indexes[:archiveSize]
+	}
+
+	for len(selectedIndexes) > archiveSize {
+		indexToRemove := 0
+		var smallestDistances []float64
+		for i, index := range selectedIndexes {
+			distancesToOthers := make([]float64, 0, len(selectedIndexes)-1)
+			for _, anotherIndex := range selectedIndexes {
+				if anotherIndex != index {
+					distancesToOthers = append(distancesToOthers, distances[index][anotherIndex])
+				}
+			}
+			sort.Float64s(distancesToOthers)
+			if i == 0 || lexicographicallyGreater(smallestDistances, distancesToOthers) {
+				indexToRemove = i
+				smallestDistances = distancesToOthers
+			}
+		}
+		selectedIndexes = append(selectedIndexes[:indexToRemove], selectedIndexes[indexToRemove+1:]...)
+	}
+	return selectedIndexes
+}
+
+// normalizedObjectiveDistances returns the Euclidean distances between the individuals after
+// scaling every objective to the range of its values in the population.
+func normalizedObjectiveDistances(population Population) [][]float64 {
+	numberOfObjectiveFunctions := len(population[0].ObjectiveValues)
+	minimumObjectiveValues := make([]float64, numberOfObjectiveFunctions)
+	objectiveRanges := make([]float64, numberOfObjectiveFunctions)
+	for k := 0; k < numberOfObjectiveFunctions; k++ {
+		minimumObjectiveValue := math.MaxFloat64
+		maximumObjectiveValue := -math.MaxFloat64
+		for _, individual := range population {
+			minimumObjectiveValue = math.Min(minimumObjectiveValue, individual.ObjectiveValues[k])
+			maximumObjectiveValue = math.Max(maximumObjectiveValue, individual.ObjectiveValues[k])
+		}
+		minimumObjectiveValues[k] = minimumObjectiveValue
+		objectiveRanges[k] = maximumObjectiveValue - minimumObjectiveValue
+		if objectiveRanges[k] <= 0 {
+			objectiveRanges[k] = 1
+		}
+	}
+
+	normalizedObjectiveValues := make([][]float64, len(population))
+	for i, individual := range population {
+		normalizedObjectiveValues[i] = make([]float64, numberOfObjectiveFunctions)
+		for k, objectiveValue := range individual.ObjectiveValues {
+			normalizedObjectiveValues[i][k] = (objectiveValue - minimumObjectiveValues[k]) / objectiveRanges[k]
+		}
+	}
+	distances := make([][]float64, len(population))
+	for i := range population {
+		distances[i] = make([]float64, len(population))
+		for j := range population {
+			distances[i][j] = euclideanDistance(normalizedObjectiveValues[i], normalizedObjectiveValues[j])
+		}
+	}
+	return distances
+}
@@ -0,0 +1,208 @@
+package nsga_iii
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// SeedingStrategy builds the genome of an individual of the initial population with a
+// placement heuristic. Ties are broken randomly, so that the seeds of a strategy differ.
+type SeedingStrategy interface {
+	Genome(problem *Problem, random *rand.Rand) []int32
+}
+
+// validatedSeedingStrategy is implemented by the seeding strategies whose configuration
+// depends on the problem.
+type validatedSeedingStrategy interface {
+	validate(problem *Problem) error
+}
+
+// SeedingRatio is the fraction of the initial population built by a seeding strategy.
+type SeedingRatio struct {
+	Strategy SeedingStrategy
+	Ratio    float64
+}
+
+// FirstFitDecreasingSeeding places the tasks, largest first, on the first node of a random
+// order they can be placed on. The size of a task is the sum of its demands relative to
+// the largest capacities.
+type FirstFitDecreasingSeeding struct{}
+
+func (seeding FirstFitDecreasingSeeding) Genome(problem *Problem, random *rand.Rand) []int32 {
+	placement := newPlacement(problem)
+	nodes := placement.shuffledNodes(random)
+	for _, t := range tasksByDecreasingSize(problem, allResourceIndexes(problem), random) {
+		placement.placeFirstFit(t, nodes)
+	}
+	return placement.genome
+}
+
+// BestFitDecreasingSeeding places the tasks, largest demand of Resource first, on the node
+// left with the smallest share of Resource remaining, which packs the tasks on few nodes.
+// Resource is ResourceMemory if empty, and must be one of the ResourceNames of the problem.
+type BestFitDecreasingSeeding struct {
+	Resource string
+}
+
+func (seeding BestFitDecreasingSeeding) resource() string {
+	if seeding.Resource == "" {
+		return ResourceMemory
+	}
+	return seeding.Resource
+}
+
+func (seeding BestFitDecreasingSeeding) validate(problem *Problem) error {
+	if _, exists := problem.ResourceIndex(seeding.resource()); !exists {
+		return fmt.Errorf("best fit decreasing seeding packs unknown resource %q, the resources are %v", seeding.resource(), problem.ResourceNames)
+	}
+	return nil
+}
+
+func (seeding BestFitDecreasingSeeding) Genome(problem *Problem, random *rand.Rand) []int32 {
+	resourceIndexes := allResourceIndexes(problem)
+	if r, exists := problem.ResourceIndex(seeding.resource()); exists {
+		resourceIndexes = []int{r}
+	}
+
+	placement := newPlacement(problem)
+	nodes := placement.shuffledNodes(random)
+	for _, t := range tasksByDecreasingSize(problem, resourceIndexes, random) {
+		placement.placeBestFit(t, nodes, resourceIndexes)
+	}
+	return placement.genome
+}
+
+// WorstFitSeeding places the tasks, largest first, on the node left with the largest share
+// of its resources remaining, which spreads the tasks over the nodes.
+type WorstFitSeeding struct{}
+
+func (seeding WorstFitSeeding) Genome(problem *Problem, random *rand.Rand) []int32 {
+	resourceIndexes := allResourceIndexes(problem)
+	placement := newPlacement(problem)
+	nodes := placement.shuffledNodes(random)
+	for _, t := range tasksByDecreasingSize(problem, resourceIndexes, random) {
+		placement.placeWorstFit(t, nodes, resourceIndexes)
+	}
+	return placement.genome
+}
+
+// RoundRobinSeeding places the tasks, in random order, on the nodes in turn: every task is
+// placed on the first node it can be placed on after the node of the previous task.
+type RoundRobinSeeding struct{}
+
+func (seeding RoundRobinSeeding) Genome(problem *Problem, random *rand.Rand) []int32 {
+	placement := newPlacement(problem)
+	nodes := placement.shuffledNodes(random)
+	tasks := random.Perm(len(problem.Tasks))
+	next := 0
+	for _, t := range tasks {
+		for i := 0; i < len(nodes); i++ {
+			nodeIndex := nodes[(next+i)%len(nodes)]
+			if placement.canPlace(t, nodeIndex) {
+				placement.place(t, nodeIndex)
+				next = (next + i + 1) % len(nodes)
+				break
+			}
+		}
+	}
+	return placement.genome
+}
+
+// PowerGreedySeeding places the tasks, largest first, on the node whose power grows the
+// least with the task, preferring the fuller node on ties, which packs the tasks on the
+// most power efficient nodes.
+type PowerGreedySeeding struct{}
+
+func (seeding PowerGreedySeeding) Genome(problem *Problem, random *rand.Rand) []int32 {
+	resourceIndexes := allResourceIndexes(problem)
+	placement := newPlacement(problem)
+	nodes := placement.shuffledNodes(random)
+	for _, t := range tasksByDecreasingSize(problem, resourceIndexes, random) {
+		bestNode := Unassigned
+		smallestPowerIncrease := math.Inf(1)
+		largestUtilization := 0.0
+		for _, nodeIndex := range nodes {
+			if !placement.canPlace(t, nodeIndex) {
+				continue
+			}
+			powerIncrease := powerIncreaseOfTask(problem.Nodes[nodeIndex], problem.Tasks[t])
+			utilization := placement.utilizationOfNode(nodeIndex)
+			if powerIncrease < smallestPowerIncrease || (powerIncrease == smallestPowerIncrease && utilization > largestUtilization) {
+				bestNode = nodeIndex
+				smallestPowerIncrease = powerIncrease
+				largestUtilization = utilization
+			}
+		}
+		if bestNode != Unassigned {
+			placement.place(t, bestNode)
+		}
+	}
+	return placement.genome
+}
+
+// powerIncreaseOfTask returns the power the node draws for the task, following the power
+// model of the power objective.
+func powerIncreaseOfTask(node Node, task Task) float64 {
+	return (node.Power.MaxPower - node.Power.IdlePower) * powerUtilizationOfNode(node, task.RequiredResources.Get)
+}
+
+func allResourceIndexes(problem *Problem) []int {
+	resourceIndexes := make([]int, len(problem.ResourceNames))
+	for r := range resourceIndexes {
+		resourceIndexes[r] = r
+	}
+	return resourceIndexes
+}
+
+// tasksByDecreasingSize returns the task indexes by decreasing sum of their demands of the
+// given resources relative to the largest capacities, tasks of the same size in random order.
+func tasksByDecreasingSize(problem *Problem, resourceIndexes []int, random *rand.Rand) []int {
+	largestCapacities := computeLargestCapacities(problem)
+	sizes := make([]float64, len(problem.Tasks))
+	for t := range problem.Tasks {
+		demands := problem.DemandsOfTask(t)
+		for _, r := range resourceIndexes {
+			if largestCapacities[r] > 0 {
+				sizes[t] += demands[r] / largestCapacities[r]
+			}
+		}
+	}
+	tasks := random.Perm(len(problem.Tasks))
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return sizes[tasks[i]] > sizes[tasks[j]]
+	})
+	return tasks
+}
+
+// validateSeedingStrategies returns an error for a missing seeding strategy or one that
+// does not apply to the problem.
+func (g GeneticAlgorithm) validateSeedingStrategies() error {
+	for i, seedingRatio := range g.SeedingStrategies {
+		if seedingRatio.Strategy == nil {
+			return fmt.Errorf("seeding ratio %d has no seeding strategy", i)
+		}
+		if validatedStrategy, isValidated := seedingRatio.Strategy.(validatedSeedingStrategy); isValidated {
+			if err := validatedStrategy.validate(g.problem); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// seedingStrategiesOfIndividuals returns the strategy building every individual of
+// the initial population after the rescheduling seeds, nil for the random individuals.
+func (g GeneticAlgorithm) seedingStrategiesOfIndividuals(numberOfReschedulingSeeds int) []SeedingStrategy {
+	seedingStrategies := make([]SeedingStrategy, g.PopulationSize)
+	i := numberOfReschedulingSeeds
+	for _, seedingRatio := range g.SeedingStrategies {
+		numberOfSeeds := int(seedingRatio.Ratio * float64(g.PopulationSize))
+		for ; numberOfSeeds > 0 && i < g.PopulationSize; numberOfSeeds-- {
+			seedingStrategies[i] = seedingRatio.Strategy
+			i++
+		}
+	}
+	return seedingStrategies
+}
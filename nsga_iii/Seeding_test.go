@@ -0,0 +1,56 @@
+package nsga_iii
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRunValidatesSeedingStrategies(t *testing.T) {
+	tests := []struct {
+		name            string
+		strategy        SeedingStrategy
+		isErrorExpected bool
+	}{
+		{name: "default resource", strategy: BestFitDecreasingSeeding{}},
+		{name: "known resource", strategy: BestFitDecreasingSeeding{Resource: ResourceCpuCores}},
+		{name: "extended resource", strategy: BestFitDecreasingSeeding{Resource: ResourceEphemeralStorage}},
+		{name: "unknown resource", strategy: BestFitDecreasingSeeding{Resource: "cpu-cores"}, isErrorExpected: true},
+		{name: "missing strategy", strategy: nil, isErrorExpected: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := GeneticAlgorithm{
+				AllNodes: []Node{
+					{ID: "n1", AvailableResources: Resources{CpuCores: 4, Memory: 4, Extended: map[string]float64{ResourceEphemeralStorage: 4}}},
+					{ID: "n2", AvailableResources: Resources{CpuCores: 4, Memory: 4, Extended: map[string]float64{ResourceEphemeralStorage: 4}}},
+				},
+				AllTasks: []Task{
+					{TaskID: "t1", RequiredResources: Resources{CpuCores: 1, Memory: 1, Extended: map[string]float64{ResourceEphemeralStorage: 1}}},
+					{TaskID: "t2", RequiredResources: Resources{CpuCores: 1, Memory: 1}},
+				},
+				PopulationSize:    4,
+				SeedingStrategies: []SeedingRatio{{Strategy: test.strategy, Ratio: 0.5}},
+			}
+			_, err := g.Run(NSGA2{})
+			if (err != nil) != test.isErrorExpected {
+				t.Fatalf("got error %v, want an error: %v", err, test.isErrorExpected)
+			}
+		})
+	}
+}
+
+func TestPowerIncreaseOfTaskMatchesPowerObjective(t *testing.T) {
+	for _, resourceWeights := range []map[string]float64{nil, {ResourceCpuCores: 2, ResourceMemory: 1}} {
+		nodes := []Node{{ID: "n1", AvailableResources: Resources{CpuCores: 4, Memory: 8}, Power: Power{IdlePower: 100, MaxPower: 300, ResourceWeights: resourceWeights}}}
+		tasks := []Task{
+			{TaskID: "t1", RequiredResources: Resources{CpuCores: 1, Memory: 2}},
+			{TaskID: "t2", RequiredResources: Resources{CpuCores: 3, Memory: 1}},
+		}
+		problem := NewProblem(nodes, tasks, nil)
+		withoutTask := problem.NewIndividual("", []int32{0, Unassigned}).computePowerObjectiveFunction()
+		withTask := problem.NewIndividual("", []int32{0, 0}).computePowerObjectiveFunction()
+		if powerIncrease := powerIncreaseOfTask(nodes[0], tasks[1]); math.Abs(withTask-withoutTask-powerIncrease) > math.Pow(10, -9) {
+			t.Fatalf("power increase of the task is %v, want %v with resource weights %v", powerIncrease, withTask-withoutTask, resourceWeights)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package nsga_iii
+
+const TaskAffinityConstraintName = "task-affinity"
+
+// TaskSelector matches tasks of any of the TaskTypes, if there are any, carrying all MatchLabels.
+type TaskSelector struct {
+	TaskTypes   []string
+	MatchLabels map[string]string
+}
+
+// TaskAffinityTerm selects the tasks a task must, or must not, share a topology domain with.
+// The domain is the node itself for TopologyKeyNode or an empty key.
+type TaskAffinityTerm struct {
+	Selector    TaskSelector
+	TopologyKey string
+}
+
+func (selector TaskSelector) matches(task Task) bool {
+	if len(selector.TaskTypes) != 0 && !containsString(selector.TaskTypes, task.TaskType) {
+		return false
+	}
+	for key, value := range selector.MatchLabels {
+		if taskValue, exists := task.Labels[key]; !exists || taskValue != value {
+			return false
+		}
+	}
+	return true
+}
+
+// TaskAffinityConstraint is violated by every pair of tasks breaking a required
+// anti-affinity term and by every task whose required affinity terms are not met
+// while tasks matching them are assigned elsewhere.
+type TaskAffinityConstraint struct{}
+
+func (constraint TaskAffinityConstraint) Name() string {
+	return TaskAffinityConstraintName
+}
+
+func (constraint TaskAffinityConstraint) Violation(individual *Individual) float64 {
+	problem := individual.Problem
+	violation := 0.0
+	for _, t := range problem.tasksWithTaskAntiAffinity {
+		nodeIndex := individual.Genome[t]
+		if nodeIndex == Unassigned {
+			continue
+		}
+		for _, term := range problem.Tasks[t].TaskAntiAffinity {
+			domains := problem.topologyDomains(term.TopologyKey)
+			domain := domains.domainOfNode[nodeIndex]
+			if domain < 0 {
+				continue
+			}
+			for _, anotherNodeIndex := range domains.nodesOfDomain[domain] {
+				for _, anotherTaskIndex := range individual.TasksOfNode(int(anotherNodeIndex)) {
+					if anotherTaskIndex != t && term.Selector.matches(problem.Tasks[anotherTaskIndex]) {
+						violation++
+					}
+				}
+			}
+		}
+	}
+
+	for _, t := range problem.tasksWithTaskAffinity {
+		nodeIndex := individual.Genome[t]
+		if nodeIndex == Unassigned {
+			continue
+		}
+		for _, term := range problem.Tasks[t].TaskAffinity {
+			domains := problem.topologyDomains(term.TopologyKey)
+			isSatisfied := false
+			isAnyMatchingTaskAssigned := false
+			for anotherTaskIndex, anotherNodeIndex := range individual.Genome {
+				if anotherNodeIndex == Unassigned || anotherTaskIndex == int(t) || !term.Selector.matches(problem.Tasks[anotherTaskIndex]) {
+					continue
+				}
+				isAnyMatchingTaskAssigned = true
+				if domains.share(nodeIndex, anotherNodeIndex) {
+					isSatisfied = true
+					break
+				}
+			}
+			if isAnyMatchingTaskAssigned && !isSatisfied {
+				violation++
+			}
+		}
+	}
+	return violation
+}
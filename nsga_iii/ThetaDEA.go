@@ -0,0 +1,136 @@
+package nsga_iii
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	defaultTheta = 5.0
+	// axisTheta is the theta of the reference directions along an objective axis, which
+	// only keep individuals close to the axis
+	axisTheta = 1e6
+)
+
+// ThetaDEA selects the survivors among the first fronts of the parents and the offspring
+// by theta-dominance: the individuals are clustered by their closest NSGA-III reference
+// direction and ranked within their cluster by their progress along the direction plus
+// Theta times their distance to it. Survivors are taken rank by rank over all clusters.
+type ThetaDEA struct {
+	NumberOfSegments int
+	// Theta weights the distance of an individual to its reference direction, 5 if not positive.
+	Theta float64
+}
+
+func (thetaDEA ThetaDEA) Initialize(g GeneticAlgorithm, population Population) error {
+	if thetaDEA.NumberOfSegments < 1 {
+		return fmt.Errorf("theta-DEA needs at least one segment per objective axis, got %d", thetaDEA.NumberOfSegments)
+	}
+	return nil
+}
+
+func (thetaDEA ThetaDEA) NextPopulation(g GeneticAlgorithm, generation int, parentPopulation Population) Population {
+	newPopulation := g.makeNewPopulationBySelection(parentPopulation, g.selectRandomIndividual)
+	unionOfParentAndNewPopulations := g.combinePopulation(parentPopulation, newPopulation)
+	if g.NormalizeConstraintViolations {
+		normalizeConstraintViolations(unionOfParentAndNewPopulations)
+	}
+
+	var candidates Population
+	for _, front := range g.nonDominatedSorter().Sort(unionOfParentAndNewPopulations) {
+		if len(candidates) >= g.PopulationSize {
+			break
+		}
+		candidates = append(candidates, *front...)
+	}
+	if len(candidates) == g.PopulationSize {
+		return candidates
+	}
+
+	referencePoints := NSGA3{}.GetReferencePoints(len(candidates[0].ObjectiveValues), thetaDEA.NumberOfSegments)
+	Normalize(candidates)
+	Associate(candidates, referencePoints)
+	clusters := make([]Population, len(referencePoints))
+	// individuals whose normalized objective values are not finite are not associated
+	var unassociatedIndividuals Population
+	for _, individual := range candidates {
+		isAssociated := false
+		for i, referencePoint := range referencePoints {
+			if individual.ReferencePoint.ID == referencePoint.ID {
+				clusters[i] = append(clusters[i], individual)
+				isAssociated = true
+			}
+		}
+		if !isAssociated {
+			unassociatedIndividuals = append(unassociatedIndividuals, individual)
+		}
+	}
+	for i, cluster := range clusters {
+		theta := thetaDEA.thetaOf(*referencePoints[i])
+		sort.SliceStable(cluster, func(a, b int) bool {
+			if comparison := cluster[a].compareConstraintViolation(*cluster[b]); comparison != 0 {
+				return comparison < 0
+			}
+			return thetaFitness(cluster[a], theta) < thetaFitness(cluster[b], theta)
+		})
+	}
+
+	nextPopulation := make(Population, 0, g.PopulationSize)
+	for rank := 0; len(nextPopulation) < g.PopulationSize; rank++ {
+		var thetaFront Population
+		for _, cluster := range clusters {
+			if rank < len(cluster) {
+				thetaFront = append(thetaFront, cluster[rank])
+			}
+		}
+		if len(thetaFront) == 0 {
+			if len(unassociatedIndividuals) == 0 {
+				break
+			}
+			thetaFront, unassociatedIndividuals = unassociatedIndividuals, nil
+		}
+		if len(nextPopulation)+len(thetaFront) > g.PopulationSize {
+			random := g.random()
+			random.Shuffle(len(thetaFront), func(i, j int) {
+				thetaFront[i], thetaFront[j] = thetaFront[j], thetaFront[i]
+			})
+			thetaFront = thetaFront[:g.PopulationSize-len(nextPopulation)]
+		}
+		nextPopulation = append(nextPopulation, thetaFront...)
+	}
+
+	for _, individual := range candidates {
+		individual.ReferencePoint = ReferencePoint{}
+		individual.PerpendicularDistance = 0
+	}
+	return nextPopulation
+}
+
+func (thetaDEA ThetaDEA) thetaOf(referencePoint ReferencePoint) float64 {
+	numberOfNonZeroCoordinates := 0
+	for _, coordinate := range referencePoint.Coordinates {
+		if coordinate != 0 {
+			numberOfNonZeroCoordinates++
+		}
+	}
+	if numberOfNonZeroCoordinates == 1 {
+		return axisTheta
+	}
+	if thetaDEA.Theta <= 0 {
+		return defaultTheta
+	}
+	return thetaDEA.Theta
+}
+
+// thetaFitness adds the distance of the individual along its reference direction to theta
+// times its perpendicular distance to the direction, in normalized objective space.
+func thetaFitness(individual *Individual, theta float64) float64 {
+	projection := 0.0
+	norm := 0.0
+	for i, coordinate := range individual.ReferencePoint.Coordinates {
+		projection += individual.NormalizedObjectiveValues[i] * coordinate
+		norm += math.Pow(coordinate, 2.0)
+	}
+	return projection/math.Sqrt(norm) + theta*individual.PerpendicularDistance
+}
@@ -0,0 +1,227 @@
+package nsga_iii
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Topology keys. A node belongs to a rack, the rack to a zone and the zone to a
+// region; any other key refers to the node label with that key.
+const (
+	TopologyKeyNode   = "node"
+	TopologyKeyRack   = "rack"
+	TopologyKeyZone   = "zone"
+	TopologyKeyRegion = "region"
+)
+
+const (
+	TopologySpreadConstraintName = "topology-spread"
+	ZoneSpreadObjectiveName      = TopologyKeyZone + "-spread"
+	RackSpreadObjectiveName      = TopologyKeyRack + "-spread"
+)
+
+// Topology places a node in the failure domain hierarchy. Empty levels fall back to
+// the node label of the corresponding topology key.
+type Topology struct {
+	Region string
+	Zone   string
+	Rack   string
+}
+
+// topologyDomain returns the domain of the node for the topology key, and false
+// when the node does not belong to any domain of that key. Rack and zone domains
+// are qualified by their parent domains, so equally named racks of different zones differ.
+func (node Node) topologyDomain(topologyKey string) (string, bool) {
+	switch topologyKey {
+	case "", TopologyKeyNode:
+		return node.ID, true
+	case TopologyKeyRegion:
+		return node.qualifiedTopologyDomain(TopologyKeyRegion)
+	case TopologyKeyZone:
+		return node.qualifiedTopologyDomain(TopologyKeyRegion, TopologyKeyZone)
+	case TopologyKeyRack:
+		return node.qualifiedTopologyDomain(TopologyKeyRegion, TopologyKeyZone, TopologyKeyRack)
+	}
+	domain, exists := node.Labels[topologyKey]
+	return domain, exists
+}
+
+func (node Node) topologyLevel(topologyKey string, value string) (string, bool) {
+	if value != "" {
+		return value, true
+	}
+	value, exists := node.Labels[topologyKey]
+	return value, exists
+}
+
+func (node Node) qualifiedTopologyDomain(topologyKeys ...string) (string, bool) {
+	values := map[string]string{
+		TopologyKeyRegion: node.Topology.Region,
+		TopologyKeyZone:   node.Topology.Zone,
+		TopologyKeyRack:   node.Topology.Rack,
+	}
+	levels := make([]string, len(topologyKeys))
+	exists := false
+	for i, topologyKey := range topologyKeys {
+		levels[i], exists = node.topologyLevel(topologyKey, values[topologyKey])
+	}
+	if !exists {
+		return "", false
+	}
+	return strings.Join(levels, "/"), true
+}
+
+// topologyDomains indexes the domains of a topology key in the order of their first node.
+type topologyDomains struct {
+	// domainOfNode is -1 for the nodes outside every domain
+	domainOfNode  []int32
+	nodesOfDomain [][]int32
+}
+
+// topologyDomains returns the domains of the topology key, indexing them on first use.
+func (problem *Problem) topologyDomains(topologyKey string) *topologyDomains {
+	problem.topologyDomainsByTopologyKeyMux.Lock()
+	defer problem.topologyDomainsByTopologyKeyMux.Unlock()
+	if domains, exists := problem.topologyDomainsByTopologyKey[topologyKey]; exists {
+		return domains
+	}
+
+	domains := &topologyDomains{domainOfNode: make([]int32, len(problem.Nodes))}
+	domainIndexByDomain := make(map[string]int32)
+	for n, node := range problem.Nodes {
+		domain, exists := node.topologyDomain(topologyKey)
+		if !exists {
+			domains.domainOfNode[n] = -1
+			continue
+		}
+		domainIndex, exists := domainIndexByDomain[domain]
+		if !exists {
+			domainIndex = int32(len(domains.nodesOfDomain))
+			domainIndexByDomain[domain] = domainIndex
+			domains.nodesOfDomain = append(domains.nodesOfDomain, nil)
+		}
+		domains.domainOfNode[n] = domainIndex
+		domains.nodesOfDomain[domainIndex] = append(domains.nodesOfDomain[domainIndex], int32(n))
+	}
+	problem.topologyDomainsByTopologyKey[topologyKey] = domains
+	return domains
+}
+
+func (domains *topologyDomains) share(nodeIndex int32, anotherNodeIndex int32) bool {
+	return domains.domainOfNode[nodeIndex] >= 0 && domains.domainOfNode[nodeIndex] == domains.domainOfNode[anotherNodeIndex]
+}
+
+// replicasOfTaskTypeByTopologyDomain counts the assigned tasks of every TaskType, by index,
+// in every domain of the topology key, including the domains without any task.
+func (individual *Individual) replicasOfTaskTypeByTopologyDomain(topologyKey string) [][]int {
+	problem := individual.Problem
+	domains := problem.topologyDomains(topologyKey)
+	replicasOfTaskTypeByDomain := make([][]int, len(domains.nodesOfDomain))
+	for domain := range replicasOfTaskTypeByDomain {
+		replicasOfTaskTypeByDomain[domain] = make([]int, len(problem.taskTypeIndexByTaskType))
+	}
+	for t, nodeIndex := range individual.Genome {
+		if nodeIndex == Unassigned {
+			continue
+		}
+		if domain := domains.domainOfNode[nodeIndex]; domain >= 0 {
+			replicasOfTaskTypeByDomain[domain][problem.taskTypeIndexes[t]]++
+		}
+	}
+	return replicasOfTaskTypeByDomain
+}
+
+// computeTopologyUniquenessObjectiveFunction penalizes replicas of the same TaskType
+// sharing a domain of the topology key, growing with the number of replicas per domain.
+func (individual *Individual) computeTopologyUniquenessObjectiveFunction(topologyKey string) int {
+	totalUniquenessObjectiveValue := 0
+	for _, replicasOfTaskType := range individual.replicasOfTaskTypeByTopologyDomain(topologyKey) {
+		domainUniquenessObjectiveValue := 0
+		for _, replicas := range replicasOfTaskType {
+			for i := 0; i < replicas; i++ {
+				domainUniquenessObjectiveValue += i + 1
+			}
+		}
+		totalUniquenessObjectiveValue += domainUniquenessObjectiveValue
+	}
+	return totalUniquenessObjectiveValue
+}
+
+// computeTopologySkews returns, for each TaskType by index, the difference between the largest
+// and the smallest number of its replicas in a domain of the topology key.
+func (individual *Individual) computeTopologySkews(topologyKey string) []int {
+	replicasOfTaskTypeByDomain := individual.replicasOfTaskTypeByTopologyDomain(topologyKey)
+	skews := make([]int, len(individual.Problem.taskTypeIndexByTaskType))
+	if len(replicasOfTaskTypeByDomain) == 0 {
+		return skews
+	}
+	for taskTypeIndex := range skews {
+		minimumReplicas := math.MaxInt64
+		maximumReplicas := 0
+		for _, replicasOfTaskType := range replicasOfTaskTypeByDomain {
+			replicas := replicasOfTaskType[taskTypeIndex]
+			if replicas < minimumReplicas {
+				minimumReplicas = replicas
+			}
+			if replicas > maximumReplicas {
+				maximumReplicas = replicas
+			}
+		}
+		skews[taskTypeIndex] = maximumReplicas - minimumReplicas
+	}
+	return skews
+}
+
+// TopologySpreadObjective penalizes replicas of the same TaskType sharing a domain of
+// the topology key, so that replicas survive the failure of a single domain.
+type TopologySpreadObjective struct {
+	TopologyKey string
+}
+
+func (objective TopologySpreadObjective) Name() string {
+	return objective.TopologyKey + "-spread"
+}
+
+func (objective TopologySpreadObjective) Direction() ObjectiveDirection {
+	return Minimize
+}
+
+func (objective TopologySpreadObjective) Evaluate(individual *Individual) float64 {
+	return float64(individual.computeTopologyUniquenessObjectiveFunction(objective.TopologyKey))
+}
+
+// TopologySpreadConstraint limits the skew of every TaskType across the domains of the
+// topology key to MaxSkew. TaskTypes restricts the constraint to the given task types.
+type TopologySpreadConstraint struct {
+	TopologyKey string
+	MaxSkew     int
+	TaskTypes   []string
+}
+
+func (constraint TopologySpreadConstraint) Name() string {
+	return TopologySpreadConstraintName + ":" + constraint.TopologyKey + ":" + strconv.Itoa(constraint.MaxSkew)
+}
+
+func (constraint TopologySpreadConstraint) Violation(individual *Individual) float64 {
+	skews := individual.computeTopologySkews(constraint.TopologyKey)
+	var taskTypeIndexes []int32
+	if len(constraint.TaskTypes) == 0 {
+		for taskTypeIndex := range skews {
+			taskTypeIndexes = append(taskTypeIndexes, int32(taskTypeIndex))
+		}
+	}
+	for _, taskType := range constraint.TaskTypes {
+		if taskTypeIndex, exists := individual.Problem.taskTypeIndexByTaskType[taskType]; exists {
+			taskTypeIndexes = append(taskTypeIndexes, taskTypeIndex)
+		}
+	}
+
+	violation := 0.0
+	for _, taskTypeIndex := range taskTypeIndexes {
+		if skews[taskTypeIndex] > constraint.MaxSkew {
+			violation += float64(skews[taskTypeIndex] - constraint.MaxSkew)
+		}
+	}
+	return violation
+}